@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/vitalii-q/selena-users-service/internal/config"
+	"github.com/vitalii-q/selena-users-service/internal/database"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/encryption"
+)
+
+// Master keys of travel document encryption (DOCUMENTS_KEYFILE):
+// docker exec -it users-service go run cmd/documents/main.go new-key -keyfile /run/secrets/documents.keys
+// in cloud: docker exec -it users-service /app/bin/documents rotate -batch 500
+// Rotation: new-key appends a key to the keyfile, restart the service so new records use it,
+// run rotate to re-wrap existing data keys, after that the old key line may be removed. rotate is safe to re-run.
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "new-key":
+		newKey(os.Args[2:])
+	case "rotate":
+		rotate(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: documents new-key -keyfile <path> | documents rotate [-batch N]")
+	os.Exit(2)
+}
+
+// newKey appends a fresh master key to the keyfile, creating it when missing; it becomes the current key
+func newKey(args []string) {
+	flags := flag.NewFlagSet("new-key", flag.ExitOnError)
+	keyfile := flags.String("keyfile", os.Getenv("DOCUMENTS_KEYFILE"), "keyfile to append the key to")
+	flags.Parse(args)
+
+	if *keyfile == "" {
+		log.Fatal("-keyfile or DOCUMENTS_KEYFILE is required")
+	}
+
+	line, err := encryption.NewKeyfileLine(time.Now())
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	file, err := os.OpenFile(*keyfile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *keyfile, err)
+	}
+	if _, err := fmt.Fprintln(file, line); err != nil {
+		log.Fatalf("Failed to write %s: %v", *keyfile, err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatalf("Failed to write %s: %v", *keyfile, err)
+	}
+
+	// the file must stay loadable, otherwise the service would not start
+	loaded, err := encryption.LoadKeyfile(*keyfile)
+	if err != nil {
+		log.Fatalf("Keyfile %s is invalid: %v", *keyfile, err)
+	}
+	log.Printf("✅ Key %s added to %s, restart the service and run rotate", loaded.CurrentKeyID(), *keyfile)
+}
+
+// rotate re-wraps data keys with the current master key of DOCUMENTS_KEYFILE
+func rotate(args []string) {
+	flags := flag.NewFlagSet("rotate", flag.ExitOnError)
+	batchSize := flags.Int("batch", services.DefaultKeyRotationBatchSize, "rows per query")
+	flags.Parse(args)
+
+	envelope, err := encryption.NewEnvelopeFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load master keys: %v", err)
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.LoadEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("🔑 Re-wrapping data keys with master key %s...", envelope.CurrentKeyID())
+
+	report, err := services.NewTravelDocumentService(db, envelope).RotateKeys(ctx, *batchSize)
+	if err != nil {
+		log.Fatalf("Rotation failed after %d re-wrapped keys: %v", report.Rewrapped, err)
+	}
+
+	log.Printf("✅ %d data keys re-wrapped", report.Rewrapped)
+}
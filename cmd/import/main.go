@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitalii-q/selena-users-service/internal/config"
+	"github.com/vitalii-q/selena-users-service/internal/database"
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+// Bulk import of users from CSV (header: first_name,last_name,email,password,role[,birth,gender,country_id,city_id])
+// or NDJSON (one object per line with the same keys):
+// docker exec -it users-service go run cmd/import/main.go -file users.csv -dry-run
+// in cloud: docker exec -it users-service /app/bin/import -file /tmp/users.ndjson -report /tmp/report.json
+// The format is taken from the file extension unless -format is given.
+func main() {
+	file := flag.String("file", "", "CSV or NDJSON file to import (required)")
+	format := flag.String("format", "", "csv or ndjson, detected from the extension by default")
+	dryRun := flag.Bool("dry-run", false, "validate and report without creating users")
+	reportPath := flag.String("report", "", "write the JSON report to this file instead of stdout")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	if *format == "" {
+		*format = formatFromExtension(*file)
+	}
+
+	input, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer input.Close()
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.LoadEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("📥 Importing users from %s (format=%s, dry-run=%t)...", *file, *format, *dryRun)
+
+	report, err := services.NewUserImportService(db, &utils.BcryptHasher{}).Import(ctx, input, *format, *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	output := os.Stdout
+	if *reportPath != "" {
+		output, err = os.Create(*reportPath)
+		if err != nil {
+			log.Fatalf("Failed to create report: %v", err)
+		}
+		defer output.Close()
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	log.Printf("✅ %d rows: %d created, %d skipped, %d failed", report.Total, report.Created, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return models.ImportFormatNDJSON
+	default:
+		return models.ImportFormatCSV
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/vitalii-q/selena-users-service/internal/config"
+	"github.com/vitalii-q/selena-users-service/internal/database"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// Register OAuth client (e.g. API gateway using /oauth2/introspect):
+// docker exec -it users-service go run cmd/oauth-client/main.go -id kong -name "Kong gateway"
+// in cloud: docker exec -it users-service /app/bin/oauth-client -id kong -name "Kong gateway"
+func main() {
+	clientID := flag.String("id", "", "client_id to register")
+	name := flag.String("name", "", "human readable client name")
+	flag.Parse()
+
+	if *clientID == "" {
+		log.Fatal("-id is required")
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.LoadEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	secret, err := services.NewAuthService(db).CreateClient(*clientID, *name)
+	if err != nil {
+		log.Fatalf("Failed to register client: %v", err)
+	}
+
+	log.Println("✅ OAuth client registered, store the secret now — it is not shown again")
+	fmt.Printf("client_id=%s\nclient_secret=%s\n", *clientID, secret)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/vitalii-q/selena-users-service/internal/config"
+	"github.com/vitalii-q/selena-users-service/internal/database"
+	"github.com/vitalii-q/selena-users-service/internal/metrics"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+)
+
+// Purge soft-deleted users after the retention period:
+// docker exec -it users-service go run cmd/purge/main.go -dry-run
+// in cloud: docker exec -it users-service /app/bin/purge -mode anonymize -retention-days 30
+// run it from cron once, or keep it running in background with -interval 24h.
+// Defaults come from PURGE_MODE, PURGE_RETENTION_DAYS, PURGE_BATCH_SIZE;
+// metrics are pushed to PUSHGATEWAY_URL when it is set.
+func main() {
+	mode := flag.String("mode", envOr("PURGE_MODE", string(services.PurgeModeDelete)), "delete (hard delete) or anonymize (keep tombstone)")
+	retentionDays := flag.Int("retention-days", envIntOr("PURGE_RETENTION_DAYS", 30), "purge users deleted more than N days ago")
+	batchSize := flag.Int("batch", envIntOr("PURGE_BATCH_SIZE", services.DefaultPurgeBatchSize), "users per transaction")
+	dryRun := flag.Bool("dry-run", false, "only report how many users would be purged")
+	interval := flag.Duration("interval", 0, "repeat every interval, 0 = run once")
+	flag.Parse()
+
+	if *retentionDays < 1 {
+		log.Fatal("-retention-days must be at least 1")
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.LoadEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
+
+	purgeService := services.NewPurgeService(db, storage.NewFromEnv())
+	opts := services.PurgeOptions{
+		Mode:      services.PurgeMode(*mode),
+		Retention: time.Duration(*retentionDays) * 24 * time.Hour,
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+	}
+
+	for {
+		if err := runPurge(ctx, purgeService, opts); err != nil {
+			if *interval == 0 {
+				log.Fatalf("Purge failed: %v", err)
+			}
+			log.Printf("Purge failed: %v", err)
+		}
+
+		if *interval == 0 {
+			return
+		}
+		time.Sleep(*interval)
+	}
+}
+
+func runPurge(ctx context.Context, purgeService *services.PurgeService, opts services.PurgeOptions) error {
+	log.Printf("🧹 Purging users soft-deleted more than %s ago (mode=%s, dry-run=%t)...", opts.Retention, opts.Mode, opts.DryRun)
+
+	result, err := purgeService.Purge(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		log.Printf("✅ Dry run %s: %d users would be purged (cutoff %s)", result.RunID, result.Candidates, result.Cutoff.Format(time.RFC3339))
+		return nil
+	}
+
+	log.Printf("✅ Purge run %s: %d of %d users purged in %d batches", result.RunID, result.Purged, result.Candidates, result.Batches)
+	pushMetrics()
+	return nil
+}
+
+// pushMetrics — the job does not serve /metrics, so successful runs are pushed to the Pushgateway
+func pushMetrics() {
+	url := os.Getenv("PUSHGATEWAY_URL")
+	if url == "" {
+		return
+	}
+
+	err := push.New(url, "users_service_purge").
+		Collector(metrics.UsersPurgedTotal).
+		Collector(metrics.PurgeLastSuccessTimestamp).
+		Push()
+	if err != nil {
+		log.Printf("Failed to push metrics: %v", err)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envIntOr(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
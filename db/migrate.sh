@@ -27,7 +27,8 @@ echo "Applying migrations from $MIGRATIONS_DIR..."
 
 FOUND_FILES=false
 
-for file in $MIGRATIONS_DIR/*.up.sql; do
+# sort -V keeps V10 after V9 (plain glob order is lexicographic)
+for file in $(ls $MIGRATIONS_DIR/*.up.sql | sort -V); do
   if [ -f "$file" ]; then
     FOUND_FILES=true
     echo "Applying migration file: $file"
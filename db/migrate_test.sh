@@ -20,7 +20,7 @@ echo "Applying migrations from db/migrations/..."
 # Применяем миграции и проверяем статус выполнения каждой
 all_migrations_successful=true
 
-for file in $ROOT_DIR/db/migrations/*.up.sql; do
+for file in $(ls $ROOT_DIR/db/migrations/*.up.sql | sort -V); do
     echo "Applying migration: $file"
     PGPASSWORD="$DB_PASSWORD" psql -h "$DB_HOST" -p "$DB_PORT" -U "$DB_USER" -d "$DB_NAME" -f "$file"
     if [ $? -ne 0 ]; then
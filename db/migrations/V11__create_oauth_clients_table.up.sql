@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    client_id VARCHAR(100) PRIMARY KEY,
+    client_secret_hash TEXT NOT NULL,
+    name VARCHAR(255),
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_users_created_at_id;
+DROP INDEX IF EXISTS idx_users_last_name_id;
+DROP INDEX IF EXISTS idx_users_first_name_id;
+DROP INDEX IF EXISTS idx_users_email_id;
+DROP INDEX IF EXISTS idx_users_role;
+DROP INDEX IF EXISTS idx_users_country_id;
+DROP INDEX IF EXISTS idx_users_city_id;
+DROP INDEX IF EXISTS idx_users_birth;
@@ -0,0 +1,11 @@
+-- keyset pagination: (sort column, id) for every sort option of GET /api/v1/users
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_last_name_id ON users((COALESCE(last_name, '')), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_first_name_id ON users((COALESCE(first_name, '')), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_email_id ON users(email, id) WHERE deleted_at IS NULL;
+
+-- filters
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+CREATE INDEX IF NOT EXISTS idx_users_country_id ON users(country_id);
+CREATE INDEX IF NOT EXISTS idx_users_city_id ON users(city_id);
+CREATE INDEX IF NOT EXISTS idx_users_birth ON users(birth);
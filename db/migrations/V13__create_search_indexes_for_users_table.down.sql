@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_search_tsv;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_last_name_trgm;
+DROP INDEX IF EXISTS idx_users_first_name_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
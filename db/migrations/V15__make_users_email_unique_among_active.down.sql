@@ -0,0 +1,3 @@
+-- fails if a deleted and an active account share an email, resolve that before rolling back
+DROP INDEX IF EXISTS users_email_active_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,4 @@
+-- email must be unique only among active accounts: a deleted user's email can be taken by a new account,
+-- restoring the deleted one then conflicts and is rejected by the API
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_key ON users(email) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS purge_runs;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS purged_at;
@@ -0,0 +1,19 @@
+-- set when the purge job anonymized a soft-deleted user, such rows are kept as tombstones
+ALTER TABLE users ADD COLUMN IF NOT EXISTS purged_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL AND purged_at IS NULL;
+
+-- one row per cmd/purge run
+CREATE TABLE IF NOT EXISTS purge_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    mode VARCHAR(20) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    cutoff TIMESTAMP NOT NULL,
+    candidates INTEGER NOT NULL DEFAULT 0,
+    purged INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_purge_runs_started_at ON purge_runs(started_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_profiles_user_id_key;
+CREATE INDEX IF NOT EXISTS idx_user_profiles_user_id ON user_profiles(user_id);
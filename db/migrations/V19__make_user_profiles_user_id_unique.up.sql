@@ -0,0 +1,3 @@
+-- one profile per user, lets the API upsert by user_id
+DROP INDEX IF EXISTS idx_user_profiles_user_id;
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_user_id_key ON user_profiles(user_id);
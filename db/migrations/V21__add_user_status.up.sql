@@ -0,0 +1,9 @@
+-- account lifecycle: pending -> active, active <-> suspended, any -> banned;
+-- a suspension with status_until in the past counts as active
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('pending', 'active', 'suspended', 'banned'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_until TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status) WHERE status <> 'active';
@@ -0,0 +1,15 @@
+-- per-user preferences, NULL means the service default applies
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    language VARCHAR(35),       -- BCP 47 tag
+    currency CHAR(3),           -- ISO 4217 code
+    timezone VARCHAR(64),       -- IANA zone name
+    units VARCHAR(8) CHECK (units IN ('metric', 'imperial')),
+    marketing_emails BOOLEAN,
+    notify_email BOOLEAN,
+    notify_sms BOOLEAN,
+    notify_push BOOLEAN,
+    consents_updated_at TIMESTAMP, -- last change of any opt-in, kept as consent evidence
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_travel_preferences;
+DROP TABLE IF EXISTS user_favorite_hotels;
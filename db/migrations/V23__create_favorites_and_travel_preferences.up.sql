@@ -0,0 +1,20 @@
+-- hotels a user marked as favorite, hotel_id is the ID in hotels-service
+CREATE TABLE IF NOT EXISTS user_favorite_hotels (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hotel_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, hotel_id)
+);
+
+-- travel preferences used for hotel recommendations, NULL means no preference
+CREATE TABLE IF NOT EXISTS user_travel_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    preferred_city_ids UUID[] NOT NULL DEFAULT '{}', -- cities of hotels-service locations
+    room_type VARCHAR(16) CHECK (room_type IN ('single', 'double', 'twin', 'suite', 'family')),
+    budget_min INTEGER CHECK (budget_min >= 0),   -- per night, whole units of currency
+    budget_max INTEGER CHECK (budget_max >= 0),
+    currency CHAR(3),                             -- ISO 4217 code of the budget
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (budget_min IS NULL OR budget_max IS NULL OR budget_min <= budget_max)
+);
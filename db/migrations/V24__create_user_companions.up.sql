@@ -0,0 +1,14 @@
+-- saved companion profiles a user books rooms for (family, colleagues)
+CREATE TABLE IF NOT EXISTS user_companions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    relationship VARCHAR(16) NOT NULL
+        CHECK (relationship IN ('spouse', 'partner', 'child', 'parent', 'sibling', 'relative', 'friend', 'colleague', 'other')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_companions_user_id ON user_companions (user_id);
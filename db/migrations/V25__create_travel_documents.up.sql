@@ -0,0 +1,19 @@
+-- passports / ID cards of users and their companions; the number is envelope-encrypted:
+-- AES-256-GCM under a per-record data key, stored wrapped by the master key key_id
+CREATE TABLE IF NOT EXISTS travel_documents (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id UUID REFERENCES user_companions(id) ON DELETE CASCADE, -- NULL: the user's own document
+    type VARCHAR(16) NOT NULL CHECK (type IN ('passport', 'id_card')),
+    country CHAR(2) NOT NULL,       -- ISO 3166-1 alpha-2 of the issuer
+    expires_on DATE,
+    number_encrypted BYTEA NOT NULL, -- nonce || ciphertext
+    number_masked VARCHAR(32) NOT NULL,
+    data_key BYTEA NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_travel_documents_user_id ON travel_documents (user_id);
+CREATE INDEX IF NOT EXISTS idx_travel_documents_key_id ON travel_documents (key_id);
@@ -0,0 +1,25 @@
+ALTER TABLE user_companions
+    ADD COLUMN IF NOT EXISTS document_type VARCHAR(16) CHECK (document_type IN ('passport', 'id_card')),
+    ADD COLUMN IF NOT EXISTS document_number VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS document_country CHAR(2),
+    ADD COLUMN IF NOT EXISTS document_expires_on DATE,
+    ADD COLUMN IF NOT EXISTS document_number_encrypted BYTEA,
+    ADD COLUMN IF NOT EXISTS document_number_masked VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS document_data_key BYTEA,
+    ADD COLUMN IF NOT EXISTS document_key_id VARCHAR(64);
+
+-- only documents moved by the up migration (id = companion_id) go back,
+-- companion documents created later stay in travel_documents
+UPDATE user_companions c
+SET document_type = d.type, document_country = d.country, document_expires_on = d.expires_on,
+    document_number_encrypted = d.number_encrypted, document_number_masked = d.number_masked,
+    document_data_key = d.data_key, document_key_id = d.key_id
+FROM travel_documents d
+WHERE d.id = c.id AND d.companion_id = c.id;
+DELETE FROM travel_documents WHERE id = companion_id;
+
+ALTER TABLE user_companions DROP CONSTRAINT IF EXISTS user_companions_document_check;
+ALTER TABLE user_companions ADD CONSTRAINT user_companions_document_check
+    CHECK ((document_type IS NULL) = (document_number IS NULL AND document_number_encrypted IS NULL));
+CREATE INDEX IF NOT EXISTS idx_user_companions_document_key_id ON user_companions (document_key_id)
+    WHERE document_key_id IS NOT NULL;
@@ -0,0 +1,37 @@
+-- companion documents are travel documents with companion_id, user_companions keeps no document columns.
+-- Encrypted numbers move with the companion's id as the document id: they stay bound to it
+-- (see numberSealedAs). Plaintext numbers written before V25 have to be sealed by cmd/documents rotate
+-- of the previous release first; until then the block fails as a whole and nothing is dropped.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'user_companions' AND column_name = 'document_number') THEN
+        IF EXISTS (SELECT 1 FROM user_companions WHERE document_number IS NOT NULL) THEN
+            RAISE EXCEPTION 'user_companions holds plaintext document numbers, run cmd/documents rotate first';
+        END IF;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'user_companions' AND column_name = 'document_number_encrypted') THEN
+        INSERT INTO travel_documents (id, user_id, companion_id, type, country, expires_on,
+            number_encrypted, number_masked, data_key, key_id, created_at, updated_at)
+        SELECT id, user_id, id, document_type, document_country, document_expires_on,
+            document_number_encrypted, document_number_masked, document_data_key, document_key_id, created_at, updated_at
+        FROM user_companions
+        WHERE document_number_encrypted IS NOT NULL
+        ON CONFLICT (id) DO NOTHING;
+    END IF;
+
+    DROP INDEX IF EXISTS idx_user_companions_document_key_id;
+    ALTER TABLE user_companions
+        DROP CONSTRAINT IF EXISTS user_companions_document_check,
+        DROP CONSTRAINT IF EXISTS user_companions_check,
+        DROP COLUMN IF EXISTS document_type,
+        DROP COLUMN IF EXISTS document_number,
+        DROP COLUMN IF EXISTS document_country,
+        DROP COLUMN IF EXISTS document_expires_on,
+        DROP COLUMN IF EXISTS document_number_encrypted,
+        DROP COLUMN IF EXISTS document_number_masked,
+        DROP COLUMN IF EXISTS document_data_key,
+        DROP COLUMN IF EXISTS document_key_id;
+END $$;
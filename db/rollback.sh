@@ -7,7 +7,7 @@ DB_NAME="users_db"
 
 echo "Rolling back migrations from db/migrations/..."
 
-for file in $(ls -r db/migrations/*.down.sql); do
+for file in $(ls db/migrations/*.down.sql | sort -rV); do
     echo "Rolling back: $file"
     PGPASSWORD="postgres" psql -h "$DB_HOST" -p "$DB_PORT" -U "$DB_USER" -d "$DB_NAME" -f "$file"
 done
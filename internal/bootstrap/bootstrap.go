@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,7 +10,11 @@ import (
 	"github.com/vitalii-q/selena-users-service/internal/database"
 	"github.com/vitalii-q/selena-users-service/internal/handlers"
 	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/encryption"
 	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+	"github.com/vitalii-q/selena-users-service/internal/services/recommendations"
+	"github.com/vitalii-q/selena-users-service/internal/services/sms"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
 	"github.com/vitalii-q/selena-users-service/internal/utils"
 )
 
@@ -18,10 +23,21 @@ type Bootstrap struct {
 	DB             *pgxpool.Pool
 	UserService        *services.UserService
 	AuthService        *services.AuthService
+	SecurityEvents     *services.SecurityEventService
 	UserHandler        *handlers.UserHandler
 	AuthHandler        *handlers.OAuthHandler
 	UserHotelsHandler  *handlers.UserHotelsHandler
 	LocationsHandler   *handlers.LocationsHandler
+	ImpersonationHandler *handlers.ImpersonationHandler
+	DataExportHandler    *handlers.DataExportHandler
+	PhoneVerificationHandler *handlers.PhoneVerificationHandler
+	AvatarHandler            *handlers.AvatarHandler
+	UserImportHandler        *handlers.UserImportHandler
+	UserStatusHandler        *handlers.UserStatusHandler
+	PreferencesHandler       *handlers.PreferencesHandler
+	TravelHandler            *handlers.TravelHandler
+	CompanionHandler         *handlers.CompanionHandler
+	TravelDocumentHandler    *handlers.TravelDocumentHandler
 }
 
 // NewBootstrap initializes all dependencies and returns Bootstrap struct
@@ -29,6 +45,10 @@ func NewBootstrap(ctx context.Context) *Bootstrap {
 	// --- Configs from .env file ---
 	env := config.LoadEnv()
 	
+	if err := utils.CheckJWTSecret(); err != nil {
+		log.Fatalf("Token signing setup failed: %v", err)
+	}
+
 	// --- Database connection ---
 	DB, err := database.Connect(ctx, env)
 	if err != nil {
@@ -41,26 +61,80 @@ func NewBootstrap(ctx context.Context) *Bootstrap {
 	// --- External services ---
 	hotelClient := external_services.NewHotelServiceClient()
 
+	// --- Encryption of travel documents ---
+	envelope, err := encryption.NewEnvelopeFromEnv()
+	if errors.Is(err, encryption.ErrNotConfigured) {
+		log.Printf("DOCUMENTS_KEYFILE is not set, travel documents are disabled")
+	} else if err != nil {
+		log.Fatalf("Documents encryption setup failed: %v", err)
+	}
+
+	// --- SMS (phone verification) ---
+	smsSender, err := sms.NewSenderFromEnv()
+	if errors.Is(err, sms.ErrNotConfigured) {
+		log.Printf("SMS_SENDER is not set, phone verification is disabled")
+	} else if err != nil {
+		log.Fatalf("SMS setup failed: %v", err)
+	}
+
+	// --- Media storage (avatars) ---
+	media := storage.NewFromEnv()
+
 	// --- Services ---
-	userService := services.NewUserService(DB, passwordHasher, hotelClient)
+	userService := services.NewUserService(DB, passwordHasher, hotelClient, media)
 	authService := services.NewAuthService(DB)
+	securityEvents := services.NewSecurityEventService(DB)
+	dataExports := services.NewDataExportService(DB)
+	go dataExports.RunCleanup(ctx)
+	phoneVerifications := services.NewPhoneVerificationService(DB, smsSender)
+	avatars := services.NewAvatarService(DB, media)
+	userImports := services.NewUserImportService(DB, passwordHasher)
+	userStatuses := services.NewUserStatusService(DB)
+	preferences := services.NewPreferencesService(DB)
+	travel := services.NewTravelService(DB, hotelClient)
+	companions := services.NewCompanionService(DB)
+	travelDocuments := services.NewTravelDocumentService(DB, envelope)
+	hotelRecommendations := services.NewHotelRecommendationService(DB, hotelClient,
+		recommendations.BuiltinStrategies(), recommendations.DefaultStrategyFromEnv())
 
 	// --- Handlers ---
 	userHandler := handlers.NewUserHandler(userService, hotelClient)
 	authHandler := &handlers.OAuthHandler{
-		UserService: userService,
-		AuthService: authService,
+		UserService:    userService,
+		AuthService:    authService,
+		SecurityEvents: securityEvents,
 	}
-	userHotelsHandler := handlers.NewUserHotelsHandler(hotelClient)
+	userHotelsHandler := handlers.NewUserHotelsHandler(hotelRecommendations)
 	locationsHandler := handlers.NewLocationsHandler(hotelClient)
+	impersonationHandler := handlers.NewImpersonationHandler(userService, authService, securityEvents)
+	dataExportHandler := handlers.NewDataExportHandler(dataExports)
+	phoneVerificationHandler := handlers.NewPhoneVerificationHandler(phoneVerifications)
+	avatarHandler := handlers.NewAvatarHandler(avatars)
+	userImportHandler := handlers.NewUserImportHandler(userImports)
+	userStatusHandler := handlers.NewUserStatusHandler(userStatuses)
+	preferencesHandler := handlers.NewPreferencesHandler(preferences)
+	travelHandler := handlers.NewTravelHandler(travel)
+	companionHandler := handlers.NewCompanionHandler(companions)
+	travelDocumentHandler := handlers.NewTravelDocumentHandler(travelDocuments)
 
 	return &Bootstrap{
 		DB:            DB,
 		UserService:       userService,
 		AuthService:       authService,
+		SecurityEvents:    securityEvents,
 		UserHandler:       userHandler,
 		AuthHandler:       authHandler,
 		UserHotelsHandler: userHotelsHandler,
 		LocationsHandler:  locationsHandler,
+		ImpersonationHandler: impersonationHandler,
+		DataExportHandler:    dataExportHandler,
+		PhoneVerificationHandler: phoneVerificationHandler,
+		AvatarHandler:            avatarHandler,
+		UserImportHandler:        userImportHandler,
+		UserStatusHandler:        userStatusHandler,
+		PreferencesHandler:       preferencesHandler,
+		TravelHandler:            travelHandler,
+		CompanionHandler:         companionHandler,
+		TravelDocumentHandler:    travelDocumentHandler,
 	}
 }
\ No newline at end of file
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
 
 type UserResponse struct {
 	ID        uuid.UUID  `json:"id"`
@@ -21,4 +25,9 @@ type UserResponse struct {
 
 	CreatedAt string     `json:"created_at"`
 	UpdatedAt string     `json:"updated_at"`
+
+	// only with ?expand=profile / identities / stats
+	Profile    *models.UserProfile   `json:"profile,omitempty"`
+	Identities []models.UserIdentity `json:"identities,omitempty"`
+	Stats      *models.UserStats     `json:"stats,omitempty"`
 }
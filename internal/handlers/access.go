@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+)
+
+// ownerOrAdmin — :id of the request when the caller is that user or an admin,
+// otherwise the error response is written and false returned
+func ownerOrAdmin(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return uuid.Nil, false
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok || (claims.UserID != id.String() && claims.Role != "admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
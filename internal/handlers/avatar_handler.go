@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+// multipartOverhead — room for boundaries and part headers on top of the file size limit
+const multipartOverhead = 64 << 10
+
+// AvatarHandler handles avatar uploads
+type AvatarHandler struct {
+	avatars *services.AvatarService
+}
+
+// NewAvatarHandler creates new handler
+func NewAvatarHandler(avatars *services.AvatarService) *AvatarHandler {
+	return &AvatarHandler{avatars: avatars}
+}
+
+// UploadAvatar replaces the avatar with the image from the "avatar" multipart field (owner or admin)
+// PUT /api/v1/users/:id/avatar
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.avatars.MaxBytes()+multipartOverhead)
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAvatarTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": "multipart field \"avatar\" is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.avatars.MaxBytes() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAvatarTooLarge.Error()})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, h.avatars.MaxBytes()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	avatar, err := h.avatars.UploadAvatar(id, data)
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAvatarTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrUnsupportedImage):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrImageDimensions):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("avatar upload failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "avatar upload failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, avatar)
+}
+
+// MediaPrefix — path the local storage serves files under, false when files are served elsewhere (S3, CDN)
+func (h *AvatarHandler) MediaPrefix() (string, bool) {
+	local, ok := h.avatars.Storage().(*storage.LocalStorage)
+	if !ok || !strings.HasPrefix(local.BaseURL, "/") {
+		return "", false
+	}
+	return local.BaseURL, true
+}
+
+// ServeMedia serves files of the local storage
+// GET <MEDIA_BASE_URL>/*path
+func (h *AvatarHandler) ServeMedia(c *gin.Context) {
+	local, ok := h.avatars.Storage().(*storage.LocalStorage)
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	local.ServeHTTP(c.Writer, c.Request)
+}
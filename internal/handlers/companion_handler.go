@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// CompanionHandler serves saved companion profiles (owner or admin),
+// their documents are served by TravelDocumentHandler with companion_id
+type CompanionHandler struct {
+	companions *services.CompanionService
+}
+
+// NewCompanionHandler creates new handler
+func NewCompanionHandler(companions *services.CompanionService) *CompanionHandler {
+	return &CompanionHandler{companions: companions}
+}
+
+// ListCompanions returns the user's companions
+// GET /api/v1/users/:id/companions
+func (h *CompanionHandler) ListCompanions(c *gin.Context) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	companions, err := h.companions.ListCompanions(c.Request.Context(), userID)
+	if err != nil {
+		respondCompanionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"companions": companions, "count": len(companions)})
+}
+
+// GetCompanion returns one companion
+// GET /api/v1/users/:id/companions/:companionId
+func (h *CompanionHandler) GetCompanion(c *gin.Context) {
+	userID, companionID, ok := companionParams(c)
+	if !ok {
+		return
+	}
+
+	companion, err := h.companions.GetCompanion(c.Request.Context(), userID, companionID)
+	if err != nil {
+		respondCompanionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companion)
+}
+
+// CreateCompanion saves a new companion
+// POST /api/v1/users/:id/companions
+func (h *CompanionHandler) CreateCompanion(c *gin.Context) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.Companion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	companion, err := h.companions.CreateCompanion(c.Request.Context(), userID, req)
+	if err != nil {
+		respondCompanionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, companion)
+}
+
+// ReplaceCompanion replaces all fields of a companion
+// PUT /api/v1/users/:id/companions/:companionId
+func (h *CompanionHandler) ReplaceCompanion(c *gin.Context) {
+	userID, companionID, ok := companionParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.Companion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	companion, err := h.companions.ReplaceCompanion(c.Request.Context(), userID, companionID, req)
+	if err != nil {
+		respondCompanionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companion)
+}
+
+// DeleteCompanion removes a companion
+// DELETE /api/v1/users/:id/companions/:companionId
+func (h *CompanionHandler) DeleteCompanion(c *gin.Context) {
+	userID, companionID, ok := companionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.companions.DeleteCompanion(c.Request.Context(), userID, companionID); err != nil {
+		respondCompanionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// companionParams — :id (checked by ownerOrAdmin) and :companionId
+func companionParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	companionID, err := uuid.Parse(c.Param("companionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, companionID, true
+}
+
+func respondCompanionError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrCompanionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCompanion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+	case errors.Is(err, services.ErrTooManyCompanions):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		logrus.WithError(err).Error("failed to process companions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process companions"})
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func setupCompanionRouter(handler *CompanionHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Authenticate(notRevokedChecker{}))
+	r.GET("/users/:id/companions/:companionId", middleware.RequireAuth(), handler.GetCompanion)
+	return r
+}
+
+func TestGetCompanion_OwnerOrAdmin(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	router := setupCompanionRouter(NewCompanionHandler(services.NewCompanionService(mockDB)))
+	userID, companionID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mockDB.ExpectQuery(`FROM user_companions WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(companionID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "first_name", "last_name", "birth_date", "relationship",
+			"created_at", "updated_at"}).
+			AddRow(companionID, userID, "Anna", "Muster", (*time.Time)(nil), "spouse", now, now))
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"/companions/"+companionID.String(), nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"first_name":"Anna"`)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+
+	// чужие компаньоны недоступны
+	req, _ = http.NewRequest("GET", "/users/"+userID.String()+"/companions/"+companionID.String(), nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "user"}))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// DataExportHandler serves GDPR data exports (right of access)
+type DataExportHandler struct {
+	exports *services.DataExportService
+}
+
+// NewDataExportHandler creates new handler
+func NewDataExportHandler(exports *services.DataExportService) *DataExportHandler {
+	return &DataExportHandler{exports: exports}
+}
+
+// GetMyExport starts an export of the caller's data or reports the running one.
+// 202 while the archive is being built, 200 with a signed download_url once it is ready.
+// GET /api/v1/me/export
+func (h *DataExportHandler) GetMyExport(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	export, err := h.exports.RequestExport(userID)
+	if err != nil {
+		logrus.WithError(err).Error("failed to request data export")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to request data export"})
+		return
+	}
+
+	if export.Status != models.DataExportReady {
+		c.JSON(http.StatusAccepted, gin.H{"export": export})
+		return
+	}
+
+	downloadURL, expires := h.exports.DownloadURL(export)
+	c.JSON(http.StatusOK, gin.H{
+		"export":               export,
+		"download_url":         downloadURL,
+		"download_url_expires": expires,
+	})
+}
+
+// Download sends the archive, the signed link itself is the authorization
+// GET /api/v1/exports/:id/download?expires=&signature=
+func (h *DataExportHandler) Download(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	path, err := h.exports.OpenDownload(id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportLinkInvalid):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrExportNotFound), errors.Is(err, services.ErrExportNotDownloadable):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("failed to open data export")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open data export"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, "selena-data-export-"+id.String()+".zip")
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"hash/fnv"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userETag — strong ETag of a user, changes with every write (users.version)
+func userETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// userFieldsETag — ETag of a ?fields= subset of the user: the version plus a hash of the
+// normalized field set, so caches never mix representations. If-Match takes the full user's tag.
+func userFieldsETag(version int, fields []string) string {
+	if fields == nil {
+		return userETag(version)
+	}
+	normalized := slices.Clone(fields)
+	slices.Sort(normalized)
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.Join(normalized, ",")))
+	return `"` + strconv.Itoa(version) + "-" + strconv.FormatUint(uint64(hash.Sum32()), 16) + `"`
+}
+
+// ifMatchVersions reads If-Match for PUT/PATCH/DELETE.
+// nil means no precondition (header absent or "*"), an empty slice matches nothing.
+// When REQUIRE_IF_MATCH=true a missing header is answered with 428 and ok=false.
+func ifMatchVersions(c *gin.Context) (versions []int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if os.Getenv("REQUIRE_IF_MATCH") == "true" {
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+			return nil, false
+		}
+		return nil, true
+	}
+	if header == "*" {
+		return nil, true
+	}
+
+	versions = []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-Match uses strong comparison, weak tags never match
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if version, err := strconv.Atoi(strings.Trim(tag, `"`)); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, true
+}
+
+// ifNoneMatchHit — true if If-None-Match contains the current ETag (weak comparison)
+func ifNoneMatchHit(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// ImpersonationHandler lets support admins act as a guest with a short-lived token
+type ImpersonationHandler struct {
+	userService    services.UserServiceInterface
+	authService    *services.AuthService
+	securityEvents *services.SecurityEventService
+}
+
+// NewImpersonationHandler creates new handler
+func NewImpersonationHandler(
+	userService services.UserServiceInterface,
+	authService *services.AuthService,
+	securityEvents *services.SecurityEventService,
+) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		userService:    userService,
+		authService:    authService,
+		securityEvents: securityEvents,
+	}
+}
+
+// StartImpersonation issues a token to act as another user
+// POST /api/v1/admin/users/:id/impersonate
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	adminID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// role in the token may be stale, re-check the admin in DB
+	admin, err := h.userService.GetUser(adminID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonationForbidden.Error()})
+		return
+	}
+
+	target, err := h.userService.GetUser(targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	token, tokenClaims, err := h.authService.IssueImpersonationToken(admin, target)
+	if err != nil {
+		if errors.Is(err, services.ErrImpersonateAdmin) || errors.Is(err, services.ErrImpersonationForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.WithError(err).Error("failed to issue impersonation token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token_generation_failed"})
+		return
+	}
+
+	logSecurityEvent(c, h.securityEvents, models.SecurityEventImpersonationStarted, &target.ID, &admin.ID, map[string]any{
+		"jti":        tokenClaims.ID,
+		"expires_at": tokenClaims.ExpiresAt.Time.UTC().Format(time.RFC3339),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":         token,
+		"token_type":           "bearer",
+		"expires_in":           int(time.Until(tokenClaims.ExpiresAt.Time).Seconds()),
+		"impersonated_user_id": target.ID,
+		"act":                  tokenClaims.Act,
+	})
+}
+
+// StopImpersonation revokes the current impersonation token
+// POST /api/v1/impersonation/stop
+func (h *ImpersonationHandler) StopImpersonation(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if !claims.IsImpersonation() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is not an impersonation token"})
+		return
+	}
+
+	if err := h.authService.RevokeToken(claims); err != nil {
+		logrus.WithError(err).Error("failed to revoke impersonation token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stop impersonation"})
+		return
+	}
+
+	var userID, adminID *uuid.UUID
+	if id, err := uuid.Parse(claims.UserID); err == nil {
+		userID = &id
+	}
+	if id, err := uuid.Parse(claims.Act.Sub); err == nil {
+		adminID = &id
+	}
+
+	logSecurityEvent(c, h.securityEvents, models.SecurityEventImpersonationStopped, userID, adminID, map[string]any{
+		"jti": claims.ID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"status": "impersonation stopped"})
+}
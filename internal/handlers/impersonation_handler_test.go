@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+// Заглушка проверки отозванных токенов
+type notRevokedChecker struct{}
+
+func (notRevokedChecker) IsTokenRevoked(claims *utils.TokenClaims) (bool, error) { return false, nil }
+
+func setupImpersonationRouter(handler *ImpersonationHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Authenticate(notRevokedChecker{}))
+	r.POST("/admin/users/:id/impersonate", middleware.RequireRole("admin"), middleware.ForbidImpersonation(), handler.StartImpersonation)
+	r.POST("/impersonation/stop", middleware.RequireAuth(), handler.StopImpersonation)
+	return r
+}
+
+func bearer(t *testing.T, claims utils.TokenClaims) string {
+	token, _, err := utils.GenerateAccessToken(claims, time.Minute)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestStartImpersonation_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mockService := new(MockUserService)
+	handler := NewImpersonationHandler(mockService, services.NewAuthService(mockDB), services.NewSecurityEventService(mockDB))
+	router := setupImpersonationRouter(handler)
+
+	admin := models.User{ID: uuid.New(), Role: "admin"}
+	guest := models.User{ID: uuid.New(), Role: "user"}
+	mockService.On("GetUser", admin.ID).Return(admin, nil)
+	mockService.On("GetUser", guest.ID).Return(guest, nil)
+
+	mockDB.ExpectExec(`INSERT INTO security_events`).
+		WithArgs(&guest.ID, &admin.ID, models.SecurityEventImpersonationStarted, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	req, _ := http.NewRequest("POST", "/admin/users/"+guest.ID.String()+"/impersonate", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: admin.ID.String(), Role: "admin"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	claims, err := utils.ParseAccessToken(resp.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, guest.ID.String(), claims.UserID)
+	assert.True(t, claims.IsImpersonation())
+	assert.Equal(t, admin.ID.String(), claims.Act.Sub)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStartImpersonation_TargetIsAdmin(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewImpersonationHandler(mockService, services.NewAuthService(nil), nil)
+	router := setupImpersonationRouter(handler)
+
+	admin := models.User{ID: uuid.New(), Role: "admin"}
+	otherAdmin := models.User{ID: uuid.New(), Role: "admin"}
+	mockService.On("GetUser", admin.ID).Return(admin, nil)
+	mockService.On("GetUser", otherAdmin.ID).Return(otherAdmin, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/users/"+otherAdmin.ID.String()+"/impersonate", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: admin.ID.String(), Role: "admin"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "admins cannot be impersonated")
+}
+
+func TestStartImpersonation_NestedImpersonationForbidden(t *testing.T) {
+	handler := NewImpersonationHandler(new(MockUserService), services.NewAuthService(nil), nil)
+	router := setupImpersonationRouter(handler)
+
+	req, _ := http.NewRequest("POST", "/admin/users/"+uuid.NewString()+"/impersonate", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{
+		UserID: uuid.NewString(),
+		Role:   "admin",
+		Act:    &utils.ActorClaim{Sub: uuid.NewString()},
+	}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestStopImpersonation_NotImpersonating(t *testing.T) {
+	handler := NewImpersonationHandler(new(MockUserService), services.NewAuthService(nil), nil)
+	router := setupImpersonationRouter(handler)
+
+	req, _ := http.NewRequest("POST", "/impersonation/stop", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,21 +1,27 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
 	//"log"
 	//"net/url"
 
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
 	"github.com/vitalii-q/selena-users-service/internal/services"
 	"github.com/vitalii-q/selena-users-service/internal/utils"
 )
 
 type OAuthHandler struct {
-	UserService *services.UserService
-	AuthService *services.AuthService
+	UserService    *services.UserService
+	AuthService    *services.AuthService
+	SecurityEvents *services.SecurityEventService
 }
 
 func (h *OAuthHandler) Authenticate(c *gin.Context) {
@@ -37,11 +43,111 @@ func (h *OAuthHandler) Authenticate(c *gin.Context) {
 		return
 	}
 
+	// status is checked after the password, so it is not disclosed to someone guessing credentials
+	status, err := h.AuthService.AccountStatus(user.ID)
+	if err != nil {
+		logrus.WithError(err).Error("failed to check account status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	if status.Status != models.UserStatusActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account_" + status.Status, "until": status.Until})
+		return
+	}
+
+	token, claims, err := h.AuthService.IssueAccessToken(user.ID, user.Role)
+	if err != nil {
+		logrus.WithError(err).Error("failed to issue access token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token_generation_failed"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"authenticated_userid": user.ID.String(),
+		"access_token":         token,
+		"token_type":           "bearer",
+		"expires_in":           int(time.Until(claims.ExpiresAt.Time).Seconds()),
 	})
 }
 
+// Introspect - RFC 7662 token introspection for gateways that cannot validate JWTs locally
+// POST /oauth2/introspect (form: token, client credentials via HTTP Basic or form)
+// Tokens come from password login, which is not bound to an OAuth client, so the response
+// has no client_id and scope: the gateway authorizes by sub and role.
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	if _, err := h.AuthService.AuthenticateClient(clientID, clientSecret); err != nil {
+		if !errors.Is(err, services.ErrInvalidClient) {
+			logrus.WithError(err).Error("client authentication failed")
+		}
+		c.Header("WWW-Authenticate", `Basic realm="introspect"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	result, err := h.AuthService.IntrospectToken(token)
+	if err != nil {
+		logrus.WithError(err).Error("token introspection failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
+
+// ChangePassword - PUT /api/v1/users/:id/password, only the account owner can do it
+func (h *OAuthHandler) ChangePassword(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok || claims.UserID != id.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	err = h.UserService.ChangePassword(id, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPassword):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	logSecurityEvent(c, h.SecurityEvents, models.SecurityEventPasswordChanged, &id, &id, nil)
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
 
 /*func (h *OAuthHandler) GetAuthorize(c *gin.Context) {
 	//clientID := c.Query("client_id")
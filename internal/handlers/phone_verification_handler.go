@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/sms"
+)
+
+// PhoneVerificationHandler verifies profile phone numbers with SMS codes
+type PhoneVerificationHandler struct {
+	verifications *services.PhoneVerificationService
+}
+
+// NewPhoneVerificationHandler creates new handler
+func NewPhoneVerificationHandler(verifications *services.PhoneVerificationService) *PhoneVerificationHandler {
+	return &PhoneVerificationHandler{verifications: verifications}
+}
+
+// SendCode sends a one-time code to the profile phone (owner only)
+// POST /api/v1/users/:id/phone/verification
+func (h *PhoneVerificationHandler) SendCode(c *gin.Context) {
+	id, ok := phoneOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.verifications.SendCode(id); err != nil {
+		respondVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "code sent"})
+}
+
+// ConfirmCode checks the code and marks the phone verified (owner only)
+// POST /api/v1/users/:id/phone/verification/confirm
+func (h *PhoneVerificationHandler) ConfirmCode(c *gin.Context) {
+	id, ok := phoneOwner(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required,len=6,numeric"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	verifiedAt, err := h.verifications.ConfirmCode(id, req.Code)
+	if err != nil {
+		respondVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"phone_verified_at": verifiedAt})
+}
+
+// phoneOwner — user ID from the path, only the user may verify their own phone
+func phoneOwner(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return uuid.Nil, false
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok || claims.UserID != id.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func respondVerificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoPhone),
+		errors.Is(err, services.ErrNoActiveVerification),
+		errors.Is(err, services.ErrVerificationCodeInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPhoneAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVerificationTooSoon), errors.Is(err, services.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, sms.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "phone verification is not available"})
+	default:
+		logrus.WithError(err).Error("phone verification failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "phone verification failed"})
+	}
+}
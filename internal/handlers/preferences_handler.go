@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// PreferencesHandler serves per-user preferences (language, currency, timezone, units, opt-ins)
+type PreferencesHandler struct {
+	preferences *services.PreferencesService
+}
+
+// NewPreferencesHandler creates new handler
+func NewPreferencesHandler(preferences *services.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{preferences: preferences}
+}
+
+// GetPreferences returns effective preferences, defaults included (owner or admin)
+// GET /api/v1/users/:id/preferences
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.preferences.GetPreferences(c.Request.Context(), id)
+	respondPreferences(c, preferences, err)
+}
+
+// PatchPreferences - RFC 7396 merge patch of the preferences, null resets one to the default
+// (owner or admin)
+// PATCH /api/v1/users/:id/preferences
+func (h *PreferencesHandler) PatchPreferences(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json"})
+		return
+	}
+
+	var changes map[string]any
+	if err := json.NewDecoder(c.Request.Body).Decode(&changes); err != nil || changes == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": "patch document must be a JSON object"})
+		return
+	}
+
+	preferences, err := h.preferences.PatchPreferences(c.Request.Context(), id, changes)
+	respondPreferences(c, preferences, err)
+}
+
+func respondPreferences(c *gin.Context, preferences models.UserPreferences, err error) {
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrInvalidPreference):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		default:
+			logrus.WithError(err).Error("failed to process user preferences")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process preferences"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func TestGetPreferences_OwnerOrAdminOnly(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	router.GET("/users/:id/preferences", middleware.RequireAuth(),
+		NewPreferencesHandler(services.NewPreferencesService(mockDB)).GetPreferences)
+
+	userID := uuid.New()
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"/preferences", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// чужие настройки недоступны
+	req, _ = http.NewRequest("GET", "/users/"+userID.String()+"/preferences", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "user"}))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
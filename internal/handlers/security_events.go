@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// logSecurityEvent — writes a security event enriched with request info.
+// Failures are only logged: the audited action has already happened at this point.
+func logSecurityEvent(
+	c *gin.Context,
+	events *services.SecurityEventService,
+	eventType string,
+	userID, actorID *uuid.UUID,
+	metadata map[string]any,
+) {
+	if events == nil {
+		return
+	}
+
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	if requestID, ok := c.Get("request_id"); ok {
+		metadata["request_id"] = requestID
+	}
+
+	err := events.Log(models.SecurityEvent{
+		UserID:    userID,
+		ActorID:   actorID,
+		EventType: eventType,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		logrus.WithError(err).Errorf("failed to log security event %s", eventType)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/services/encryption"
+)
+
+// TravelDocumentHandler serves passports / ID cards of users and their companions (owner or admin);
+// numbers are masked, the full number only comes from the audited reveal endpoint
+type TravelDocumentHandler struct {
+	documents *services.TravelDocumentService
+}
+
+// NewTravelDocumentHandler creates new handler
+func NewTravelDocumentHandler(documents *services.TravelDocumentService) *TravelDocumentHandler {
+	return &TravelDocumentHandler{documents: documents}
+}
+
+// ListDocuments returns the user's documents with masked numbers
+// GET /api/v1/users/:id/documents
+func (h *TravelDocumentHandler) ListDocuments(c *gin.Context) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	documents, err := h.documents.ListDocuments(c.Request.Context(), userID)
+	if err != nil {
+		respondTravelDocumentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": documents, "count": len(documents)})
+}
+
+// GetDocument returns one document with the masked number
+// GET /api/v1/users/:id/documents/:documentId
+func (h *TravelDocumentHandler) GetDocument(c *gin.Context) {
+	userID, documentID, ok := travelDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.documents.GetDocument(c.Request.Context(), userID, documentID)
+	if err != nil {
+		respondTravelDocumentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// CreateDocument stores a document, the number is encrypted
+// POST /api/v1/users/:id/documents {"type": "passport", "number": "...", "country": "DE", "expires_on": "2030-01-31", "companion_id": null}
+func (h *TravelDocumentHandler) CreateDocument(c *gin.Context) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.TravelDocument
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	document, err := h.documents.CreateDocument(c.Request.Context(), userID, req)
+	if err != nil {
+		respondTravelDocumentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// DeleteDocument removes a document
+// DELETE /api/v1/users/:id/documents/:documentId
+func (h *TravelDocumentHandler) DeleteDocument(c *gin.Context) {
+	userID, documentID, ok := travelDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.documents.DeleteDocument(c.Request.Context(), userID, documentID); err != nil {
+		respondTravelDocumentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevealDocument returns the document with the full number, the access is audited with the reason
+// POST /api/v1/users/:id/documents/:documentId/reveal {"reason": "check-in at hotel ..."}
+func (h *TravelDocumentHandler) RevealDocument(c *gin.Context) {
+	userID, documentID, ok := travelDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	document, err := h.documents.RevealDocument(c.Request.Context(), userID, documentID, req.Reason, requestAudit(c, req.Reason))
+	if err != nil {
+		respondTravelDocumentError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, document)
+}
+
+// travelDocumentParams — :id (checked by ownerOrAdmin) and :documentId
+func travelDocumentParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := ownerOrAdmin(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	documentID, err := uuid.Parse(c.Param("documentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, documentID, true
+}
+
+func respondTravelDocumentError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrTravelDocumentNotFound), errors.Is(err, services.ErrCompanionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTravelDocument):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+	case errors.Is(err, services.ErrRevealReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyTravelDocuments):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, encryption.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "documents storage is not configured"})
+	default:
+		logrus.WithError(err).Error("failed to process travel documents")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process travel documents"})
+	}
+}
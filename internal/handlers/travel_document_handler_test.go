@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func TestTravelDocuments_WithoutMasterKey(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewTravelDocumentHandler(services.NewTravelDocumentService(mockDB, nil))
+	router := gin.New()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	router.POST("/users/:id/documents", middleware.RequireAuth(), handler.CreateDocument)
+	router.POST("/users/:id/documents/:documentId/reveal", middleware.RequireAuth(), handler.RevealDocument)
+
+	userID := uuid.New()
+	token := bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"})
+	send := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// без DOCUMENTS_KEYFILE документы не принимаются
+	w := send("/users/"+userID.String()+"/documents", `{"type":"passport","number":"C01X00T47","country":"DE"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// причина раскрытия обязательна
+	w = send("/users/"+userID.String()+"/documents/"+uuid.NewString()+"/reveal", `{"reason":""}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// TravelHandler serves favorite hotels and travel preferences (owner or admin)
+type TravelHandler struct {
+	travel *services.TravelService
+}
+
+// NewTravelHandler creates new handler
+func NewTravelHandler(travel *services.TravelService) *TravelHandler {
+	return &TravelHandler{travel: travel}
+}
+
+type addFavoriteRequest struct {
+	HotelID string `json:"hotel_id" binding:"required"`
+}
+
+// ListFavorites returns favorite hotels, newest first
+// GET /api/v1/users/:id/favorites
+func (h *TravelHandler) ListFavorites(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	favorites, err := h.travel.ListFavorites(c.Request.Context(), id)
+	if err != nil {
+		respondTravelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"favorites": favorites})
+}
+
+// AddFavorite marks a hotel as favorite: 201 when added, 200 when it already was one
+// POST /api/v1/users/:id/favorites
+func (h *TravelHandler) AddFavorite(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req addFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	favorite, created, err := h.travel.AddFavorite(c.Request.Context(), id, req.HotelID)
+	if err != nil {
+		respondTravelError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, favorite)
+}
+
+// RemoveFavorite unmarks a favorite hotel
+// DELETE /api/v1/users/:id/favorites/:hotelId
+func (h *TravelHandler) RemoveFavorite(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	if err := h.travel.RemoveFavorite(c.Request.Context(), id, c.Param("hotelId")); err != nil {
+		respondTravelError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTravelPreferences returns preferred cities, room type and budget range
+// GET /api/v1/users/:id/preferences/travel
+func (h *TravelHandler) GetTravelPreferences(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.travel.GetTravelPreferences(c.Request.Context(), id)
+	if err != nil {
+		respondTravelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// ReplaceTravelPreferences replaces the travel preferences, omitted fields are cleared
+// PUT /api/v1/users/:id/preferences/travel
+func (h *TravelHandler) ReplaceTravelPreferences(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.TravelPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	preferences, err := h.travel.ReplaceTravelPreferences(c.Request.Context(), id, req)
+	if err != nil {
+		respondTravelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+func respondTravelError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrFavoriteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTravelPreferences):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+	case errors.Is(err, services.ErrUnknownHotel), errors.Is(err, services.ErrTooManyFavorites):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrHotelServiceUnavailable):
+		logrus.WithError(err).Warn("hotel service unavailable")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "hotel service unavailable"})
+	default:
+		logrus.WithError(err).Error("failed to process travel data")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process travel data"})
+	}
+}
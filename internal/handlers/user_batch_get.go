@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// batchGetRequest — body of POST /users:batchGet
+type batchGetRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// BatchGetUsersHandler — many users by ID in one round trip, for services resolving user references.
+// Users come back in request order, IDs that are unknown or deleted are listed in "missing"
+// POST /api/v1/users:batchGet?expand=&fields=
+func (h *UserHandler) BatchGetUsersHandler(c *gin.Context) {
+	var req batchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	ids, err := parseIDList(req.IDs, services.MaxBatchGetIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	expanders, err := parseExpand(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+	if !privateExpandAllowed(c, expanders, ids) {
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+
+	found, err := h.service.GetUsersByIDs(ids, fields)
+	if err != nil {
+		logrus.WithError(err).Error("failed to batch get users")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch users"})
+		return
+	}
+
+	byID := make(map[uuid.UUID]models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	users := make([]models.User, 0, len(found))
+	missing := []uuid.UUID{}
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	expanders = withFieldExpanders(fields, expanders)
+	responses, err := h.expandUsers(users, expanders)
+	if err != nil {
+		logrus.WithError(err).Error("failed to expand users")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to expand users"})
+		return
+	}
+
+	var result any = responses
+	if fields != nil {
+		if result, err = sparseResponses(responses, fields, expanders); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":   result,
+		"missing": missing,
+	})
+}
+
+// parseIDList — validated UUIDs without duplicates, first occurrence order, at most max distinct ones
+func parseIDList(values []string, max int) ([]uuid.UUID, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("ids must not be empty")
+	}
+
+	ids := make([]uuid.UUID, 0, len(values))
+	seen := make(map[uuid.UUID]bool, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UUID %q", value)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	if len(ids) > max {
+		return nil, fmt.Errorf("at most %d ids per request, got %d", max, len(ids))
+	}
+	return ids, nil
+}
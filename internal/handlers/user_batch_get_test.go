@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func setupBatchGetRouter(mockService *MockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	handler := &UserHandler{service: mockService}
+	router.POST("/users:batchGet", handler.BatchGetUsersHandler)
+	return router
+}
+
+func batchGetRequestBody(ids ...string) *strings.Reader {
+	body, _ := json.Marshal(map[string][]string{"ids": ids})
+	return strings.NewReader(string(body))
+}
+
+func TestBatchGetUsersHandler_FoundAndMissing(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupBatchGetRouter(mockService)
+
+	first, second, unknown := uuid.New(), uuid.New(), uuid.New()
+	// дубликаты убираются, в запрос уходит один ANY по уникальным ID
+	mockService.On("GetUsersByIDs", []uuid.UUID{second, unknown, first}, []string(nil)).Return([]models.User{
+		{ID: first, FirstName: "John"},
+		{ID: second, FirstName: "Jane"},
+	}, nil).Once()
+
+	req, _ := http.NewRequest("POST", "/users:batchGet",
+		batchGetRequestBody(second.String(), unknown.String(), first.String(), second.String()))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Users []struct {
+			ID        uuid.UUID `json:"id"`
+			FirstName string    `json:"first_name"`
+		} `json:"users"`
+		Missing []uuid.UUID `json:"missing"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	// порядок запроса сохраняется
+	assert.Len(t, resp.Users, 2)
+	assert.Equal(t, second, resp.Users[0].ID)
+	assert.Equal(t, first, resp.Users[1].ID)
+	assert.Equal(t, []uuid.UUID{unknown}, resp.Missing)
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchGetUsersHandler_SparseFieldsWithExpand(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupBatchGetRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("GetUsersByIDs", []uuid.UUID{userID}, []string{"email"}).
+		Return([]models.User{{ID: userID, Email: "john@example.com"}}, nil)
+	mockService.On("GetIdentities", []uuid.UUID{userID}).Return(map[uuid.UUID][]models.UserIdentity{
+		userID: {{Provider: "github", ProviderID: "gh-1"}},
+	}, nil)
+
+	req, _ := http.NewRequest("POST", "/users:batchGet?fields=email&expand=identities", batchGetRequestBody(userID.String()))
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string][]map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "john@example.com", resp["users"][0]["email"])
+	assert.Contains(t, resp["users"][0], "identities")
+	assert.NotContains(t, resp["users"][0], "first_name")
+	assert.Empty(t, resp["missing"])
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchGetUsersHandler_InvalidRequests(t *testing.T) {
+	tooMany := make([]string, services.MaxBatchGetIDs+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.NewString()
+	}
+
+	tests := map[string]struct {
+		path string
+		ids  []string
+	}{
+		"empty list":     {path: "/users:batchGet", ids: []string{}},
+		"invalid uuid":   {path: "/users:batchGet", ids: []string{"not-a-uuid"}},
+		"too many ids":   {path: "/users:batchGet", ids: tooMany},
+		"unknown expand": {path: "/users:batchGet?expand=nope", ids: []string{uuid.NewString()}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			router := setupBatchGetRouter(mockService)
+
+			req, _ := http.NewRequest("POST", tt.path, batchGetRequestBody(tt.ids...))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "GetUsersByIDs", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// bulkUsersRequest — selects users by "ids" or by "filter", a filter expression in the listing
+// query string syntax ("role=user&created_to=2024-01-01"), so a list URL can be reused as is
+type bulkUsersRequest struct {
+	IDs    []string `json:"ids"`
+	Filter string   `json:"filter"`
+	Reason string   `json:"reason"` // stored in the audit trail
+}
+
+// bulkUpdateRequest — body of POST /admin/users:batchUpdate
+type bulkUpdateRequest struct {
+	bulkUsersRequest
+	Changes struct {
+		Role *string `json:"role" binding:"omitempty,oneof=admin user"`
+	} `json:"changes"`
+}
+
+// BulkUpdateUsersHandler — sets the given fields (currently role) on many users in one transaction
+// POST /api/v1/admin/users:batchUpdate {"ids": [...] | "filter": "...", "changes": {"role": "admin"}}
+func (h *UserHandler) BulkUpdateUsersHandler(c *gin.Context) {
+	var req bulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	selector, err := parseBulkSelector(req.bulkUsersRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	changes := models.BulkUserChanges{Role: req.Changes.Role}
+	report, err := h.service.BulkUpdateUsers(c.Request.Context(), selector, changes, requestAudit(c, req.Reason))
+	respondBulk(c, report, err)
+}
+
+// BulkDeleteUsersHandler — soft-deletes many users in one transaction
+// POST /api/v1/admin/users:batchDelete {"ids": [...] | "filter": "..."}
+func (h *UserHandler) BulkDeleteUsersHandler(c *gin.Context) {
+	var req bulkUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	selector, err := parseBulkSelector(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	report, err := h.service.BulkDeleteUsers(c.Request.Context(), selector, requestAudit(c, req.Reason))
+	respondBulk(c, report, err)
+}
+
+// parseBulkSelector — exactly one of ids / filter; deleted-scope flags make no sense for bulk
+// changes and an empty filter would select everybody, both are rejected
+func parseBulkSelector(req bulkUsersRequest) (models.BulkUserSelector, error) {
+	switch {
+	case len(req.IDs) > 0 && req.Filter == "":
+		ids, err := parseIDList(req.IDs, services.MaxBulkUsers)
+		if err != nil {
+			return models.BulkUserSelector{}, err
+		}
+		return models.BulkUserSelector{IDs: ids}, nil
+
+	case len(req.IDs) == 0 && req.Filter != "":
+		values, err := url.ParseQuery(req.Filter)
+		if err != nil {
+			return models.BulkUserSelector{}, fmt.Errorf("invalid filter: %w", err)
+		}
+		filter, err := parseUserFilterValues(values.Get)
+		if err != nil {
+			return models.BulkUserSelector{}, err
+		}
+		if filter.IncludeDeleted || filter.OnlyDeleted {
+			return models.BulkUserSelector{}, fmt.Errorf("include_deleted and only_deleted are not supported in bulk operations")
+		}
+		if filter == (models.UserFilter{}) {
+			return models.BulkUserSelector{}, fmt.Errorf("filter has no known conditions")
+		}
+		return models.BulkUserSelector{Filter: &filter}, nil
+	}
+
+	return models.BulkUserSelector{}, services.ErrInvalidSelector
+}
+
+func respondBulk(c *gin.Context, report models.BulkUserReport, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBulkNoChanges), errors.Is(err, services.ErrInvalidSelector):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrBulkTooLarge):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "details": "narrow the filter or split the request"})
+		default:
+			logrus.WithError(err).Error("bulk user operation failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "bulk operation failed, nothing was changed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+func setupBulkRouter(mockService *MockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	handler := &UserHandler{service: mockService}
+	router.POST("/admin/users/batchUpdate", handler.BulkUpdateUsersHandler)
+	router.POST("/admin/users/batchDelete", handler.BulkDeleteUsersHandler)
+	return router
+}
+
+func TestBulkUpdateUsersHandler_ByFilter(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupBulkRouter(mockService)
+
+	role, admin := "user", "admin"
+	userID := uuid.New()
+	mockService.On("BulkUpdateUsers",
+		models.BulkUserSelector{Filter: &models.UserFilter{Role: &role}},
+		models.BulkUserChanges{Role: &admin},
+		mock.MatchedBy(func(audit models.AuditLog) bool { return audit.Metadata["reason"] == "promotion" }),
+	).Return(models.BulkUserReport{
+		Total: 1, Changed: 1,
+		Items: []models.BulkUserItem{{ID: userID, Status: models.BulkItemUpdated}},
+	}, nil)
+
+	body := `{"filter": "role=user", "changes": {"role": "admin"}, "reason": "promotion"}`
+	req, _ := http.NewRequest("POST", "/admin/users/batchUpdate", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"updated"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestBulkDeleteUsersHandler_ByIDs(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupBulkRouter(mockService)
+
+	first, second := uuid.New(), uuid.New()
+	mockService.On("BulkDeleteUsers", models.BulkUserSelector{IDs: []uuid.UUID{first, second}}, mock.Anything).
+		Return(models.BulkUserReport{Total: 2, Changed: 2}, nil)
+
+	body := `{"ids": ["` + first.String() + `", "` + second.String() + `", "` + first.String() + `"]}`
+	req, _ := http.NewRequest("POST", "/admin/users/batchDelete", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBulkDeleteUsersHandler_FilterTooBroad(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupBulkRouter(mockService)
+
+	mockService.On("BulkDeleteUsers", mock.Anything, mock.Anything).
+		Return(models.BulkUserReport{}, services.ErrBulkTooLarge)
+
+	req, _ := http.NewRequest("POST", "/admin/users/batchDelete", strings.NewReader(`{"filter": "created_to=2020-01-01"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestBulkUsersHandlers_InvalidSelector(t *testing.T) {
+	tests := map[string]string{
+		"no selector":     `{"changes": {"role": "admin"}}`,
+		"ids and filter":  `{"ids": ["` + uuid.NewString() + `"], "filter": "role=user", "changes": {"role": "admin"}}`,
+		"deleted scope":   `{"filter": "role=user&include_deleted=true", "changes": {"role": "admin"}}`,
+		"unknown filter":  `{"filter": "nickname=bob", "changes": {"role": "admin"}}`,
+		"invalid role":    `{"filter": "role=user", "changes": {"role": "root"}}`,
+		"invalid id":      `{"ids": ["nope"], "changes": {"role": "admin"}}`,
+		"filter bad date": `{"filter": "created_to=yesterday", "changes": {"role": "admin"}}`,
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			router := setupBulkRouter(mockService)
+
+			req, _ := http.NewRequest("POST", "/admin/users/batchUpdate", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "BulkUpdateUsers", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/dto"
+	"github.com/vitalii-q/selena-users-service/internal/helpers"
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+)
+
+// userExpander — one ?expand= option, loads its data for all users of the response at once
+type userExpander struct {
+	name    string
+	fields  []string // response keys it fills, kept in sparse fieldsets
+	private bool     // personal data: only for the user's own account or an admin, see privateExpandAllowed
+	expand  func(h *UserHandler, responses []dto.UserResponse) error
+}
+
+// userExpanders — supported ?expand= values, register new expansions here
+var userExpanders = []userExpander{
+	{name: "locations", fields: []string{"country", "city"}, expand: (*UserHandler).attachLocations},
+	{name: "profile", fields: []string{"profile"}, private: true, expand: (*UserHandler).attachProfiles},
+	{name: "identities", fields: []string{"identities"}, private: true, expand: (*UserHandler).attachIdentities},
+	{name: "stats", fields: []string{"stats"}, private: true, expand: (*UserHandler).attachStats},
+}
+
+// parseExpand — ?expand=locations,profile -> requested expanders in registration order,
+// unknown names are an error
+func parseExpand(c *gin.Context) ([]userExpander, error) {
+	requested := map[string]bool{}
+	for _, value := range strings.Split(c.Query("expand"), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			requested[value] = true
+		}
+	}
+
+	var expanders []userExpander
+	for _, expander := range userExpanders {
+		if requested[expander.name] {
+			expanders = append(expanders, expander)
+			delete(requested, expander.name)
+		}
+	}
+	for name := range requested {
+		return nil, fmt.Errorf("unknown expand %q, supported: %s", name, expanderNames())
+	}
+
+	return expanders, nil
+}
+
+// privateExpandAllowed — private expansions are only allowed when every user of the response is
+// the caller, or the caller is an admin; otherwise 403 is written and false returned
+func privateExpandAllowed(c *gin.Context, expanders []userExpander, ids []uuid.UUID) bool {
+	for _, expander := range expanders {
+		if !expander.private {
+			continue
+		}
+		claims, ok := middleware.GetClaims(c)
+		if ok && claims.Role == "admin" {
+			return true
+		}
+		for _, id := range ids {
+			if !ok || claims.UserID != id.String() {
+				c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("expand=%s is only available for your own account", expander.name)})
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func expanderNames() string {
+	names := make([]string, 0, len(userExpanders))
+	for _, expander := range userExpanders {
+		names = append(names, expander.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// withFieldExpanders — country / city in ?fields= are names, they need the locations expander
+func withFieldExpanders(fields []string, expanders []userExpander) []userExpander {
+	if !slices.Contains(fields, "country") && !slices.Contains(fields, "city") {
+		return expanders
+	}
+	for _, expander := range expanders {
+		if expander.name == "locations" {
+			return expanders
+		}
+	}
+	// locations is registered first, expanders stay in registration order
+	return append([]userExpander{userExpanders[0]}, expanders...)
+}
+
+// sparseResponses — only the requested fields plus whatever the expanders added
+func sparseResponses(responses []dto.UserResponse, fields []string, expanders []userExpander) ([]map[string]any, error) {
+	keep := slices.Clone(fields)
+	for _, expander := range expanders {
+		keep = append(keep, expander.fields...)
+	}
+
+	result := make([]map[string]any, 0, len(responses))
+	for _, response := range responses {
+		raw, err := json.Marshal(response)
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]any
+		if err := json.Unmarshal(raw, &full); err != nil {
+			return nil, err
+		}
+
+		sparse := make(map[string]any, len(keep))
+		for _, key := range keep {
+			if value, ok := full[key]; ok {
+				sparse[key] = value
+			}
+		}
+		result = append(result, sparse)
+	}
+
+	return result, nil
+}
+
+// expandUsers — response DTOs with every requested expansion applied
+func (h *UserHandler) expandUsers(users []models.User, expanders []userExpander) ([]dto.UserResponse, error) {
+	responses := helpers.ToUserResponses(users)
+	for _, expander := range expanders {
+		if err := expander.expand(h, responses); err != nil {
+			return nil, fmt.Errorf("expand %s: %w", expander.name, err)
+		}
+	}
+	return responses, nil
+}
+
+func responseIDs(responses []dto.UserResponse) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(responses))
+	for _, response := range responses {
+		ids = append(ids, response.ID)
+	}
+	return ids
+}
+
+// attachLocations - country / city names, one locations request for the whole page
+func (h *UserHandler) attachLocations(responses []dto.UserResponse) error {
+	return helpers.AttachLocations(responses, h.HotelServiceClient)
+}
+
+// attachProfiles - fills Profile of every response with one batch query
+func (h *UserHandler) attachProfiles(responses []dto.UserResponse) error {
+	profiles, err := h.service.GetProfiles(responseIDs(responses))
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		if profile, ok := profiles[responses[i].ID]; ok {
+			responses[i].Profile = &profile
+		}
+	}
+
+	return nil
+}
+
+// attachIdentities - linked OAuth identities, one batch query
+func (h *UserHandler) attachIdentities(responses []dto.UserResponse) error {
+	identities, err := h.service.GetIdentities(responseIDs(responses))
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		responses[i].Identities = identities[responses[i].ID]
+	}
+
+	return nil
+}
+
+// attachStats - activity counters, one batch query
+func (h *UserHandler) attachStats(responses []dto.UserResponse) error {
+	stats, err := h.service.GetStats(responseIDs(responses))
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		if userStats, ok := stats[responses[i].ID]; ok {
+			responses[i].Stats = &userStats
+		}
+	}
+
+	return nil
+}
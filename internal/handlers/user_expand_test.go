@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func setupExpandRouter(mockService *MockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	handler := &UserHandler{service: mockService}
+	router.GET("/users", handler.GetUsersHandler)
+	router.GET("/users/:id", handler.GetUserHandler)
+	return router
+}
+
+func TestGetUserHandler_ExpandIdentitiesAndStats(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	userID := uuid.New()
+	lastLogin := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	mockService.On("GetUser", userID).Return(models.User{ID: userID, Version: 1}, nil)
+	mockService.On("GetIdentities", []uuid.UUID{userID}).Return(map[uuid.UUID][]models.UserIdentity{
+		userID: {{Provider: "google", ProviderID: "g-1"}},
+	}, nil)
+	mockService.On("GetStats", []uuid.UUID{userID}).Return(map[uuid.UUID]models.UserStats{
+		userID: {LoginCount: 3, LastLoginAt: &lastLogin},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"?expand=stats,identities", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	var resp map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "google", resp["identities"].([]any)[0].(map[string]any)["provider"])
+	assert.Equal(t, float64(3), resp["stats"].(map[string]any)["login_count"])
+	// локации не запрашивались — HotelService не вызывается
+	assert.Nil(t, resp["country"])
+	assert.NotContains(t, resp, "profile")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUsersHandler_ExpandBatchesPerPage(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	first, second := uuid.New(), uuid.New()
+	mockService.On("ListUsers", models.UserListParams{}).Return(models.UserPage{
+		Users: []models.User{{ID: first}, {ID: second}},
+	}, nil)
+	// один батч-запрос на страницу, а не на каждого пользователя
+	mockService.On("GetIdentities", []uuid.UUID{first, second}).
+		Return(map[uuid.UUID][]models.UserIdentity{}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/users?expand=identities", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "admin"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestExpand_UnknownOption(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	for _, url := range []string{"/users?expand=friends", "/users/" + uuid.NewString() + "?expand=profile,friends"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		assert.Contains(t, w.Body.String(), "friends")
+	}
+	mockService.AssertNotCalled(t, "ListUsers")
+	mockService.AssertNotCalled(t, "GetUser")
+}
+
+func TestGetUserHandler_SparseFields(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("GetUserFields", userID, []string{"id", "first_name"}).
+		Return(models.User{ID: userID, FirstName: "John", Version: 2}, nil)
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"?fields=id,first_name", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// у подмножества полей свой ETag, порядок полей не важен
+	etag := w.Header().Get("ETag")
+	assert.NotEqual(t, `"2"`, etag)
+	assert.Equal(t, userFieldsETag(2, []string{"first_name", "id"}), etag)
+
+	var resp map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]any{"id": userID.String(), "first_name": "John"}, resp)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUsersHandler_SparseFieldsWithExpand(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("ListUsers", models.UserListParams{Fields: []string{"email"}}).Return(models.UserPage{
+		Users: []models.User{{ID: userID, Email: "john@example.com"}},
+	}, nil)
+	mockService.On("GetStats", []uuid.UUID{userID}).Return(map[uuid.UUID]models.UserStats{
+		userID: {LoginCount: 1},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/users?fields=email&expand=stats", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "admin"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Users []map[string]any `json:"users"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	// расширения остаются в ответе вместе с запрошенными полями
+	assert.ElementsMatch(t, []string{"email", "stats"}, keys(resp.Users[0]))
+	mockService.AssertExpectations(t)
+}
+
+func TestFields_NotInAllowList(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	for _, url := range []string{"/users?fields=id,password", "/users/" + uuid.NewString() + "?fields=deleted_at"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+	mockService.AssertNotCalled(t, "ListUsers")
+}
+
+func keys(m map[string]any) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
+
+func TestGetUserHandler_ExpandProfileRequiresOwner(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	// анонимный запрос не получает телефон и адрес пользователя
+	req, _ := http.NewRequest("GET", "/users/"+uuid.NewString()+"?expand=profile", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "GetUser")
+	mockService.AssertNotCalled(t, "GetProfiles")
+}
+
+func TestExpand_IdentitiesAndStatsRequireOwner(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	userID, other := uuid.New(), uuid.New()
+	mockService.On("ListUsers", models.UserListParams{}).Return(models.UserPage{
+		Users: []models.User{{ID: userID}, {ID: other}},
+	}, nil)
+
+	// входы через соцсети и активность видны только самому пользователю и админу
+	for _, url := range []string{"/users/" + other.String() + "?expand=identities", "/users/" + other.String() + "?expand=stats", "/users?expand=stats"} {
+		for _, token := range []string{"", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"})} {
+			req, _ := http.NewRequest("GET", url, nil)
+			if token != "" {
+				req.Header.Set("Authorization", token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, url)
+		}
+	}
+	mockService.AssertNotCalled(t, "GetIdentities")
+	mockService.AssertNotCalled(t, "GetStats")
+}
+
+func TestGetUserHandler_ExpandIgnoresIfNoneMatch(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExpandRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("GetUser", userID).Return(models.User{ID: userID, Version: 4}, nil)
+	mockService.On("GetStats", []uuid.UUID{userID}).Return(map[uuid.UUID]models.UserStats{
+		userID: {LoginCount: 5},
+	}, nil)
+
+	// статистика меняется без изменения users.version — 304 по версии был бы устаревшим ответом
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"?expand=stats", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"}))
+	req.Header.Set("If-None-Match", `"4"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"login_count":5`)
+	mockService.AssertExpectations(t)
+}
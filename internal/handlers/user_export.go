@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/dto"
+	"github.com/vitalii-q/selena-users-service/internal/helpers"
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+const (
+	exportFlushEvery          = 500  // rows between flushes of the response
+	defaultExportRowGroupSize = 1000 // rows per row group of the columnar format
+	maxExportRowGroupSize     = 100000
+)
+
+// exportFields — columns of an export without ?fields=, country / city only with ?expand=locations
+var exportFields = []string{"id", "first_name", "last_name", "email", "role", "birth", "gender",
+	"country_id", "city_id", "created_at", "updated_at"}
+
+// userExportWriter — encodes exported rows in one format
+type userExportWriter interface {
+	Write(row []any) error
+	Close() error
+}
+
+// ExportUsersHandler — streams users matching the listing filters (admin only)
+// GET /api/v1/users/export?format=csv|ndjson|columnar&fields=&expand=locations&row_group_size=
+// filters are the same as for GET /api/v1/users (role, gender, country_id, ..., include_deleted)
+func (h *UserHandler) ExportUsersHandler(c *gin.Context) {
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+	if !deletedScopeAllowed(c, filter) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "include_deleted and only_deleted require admin role"})
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+
+	if fields == nil {
+		fields = exportFields
+	}
+	switch c.Query("expand") {
+	case "":
+	case "locations":
+		for _, field := range []string{"country", "city"} {
+			if !slices.Contains(fields, field) {
+				fields = append(fields[:len(fields):len(fields)], field)
+			}
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "export supports only expand=locations"})
+		return
+	}
+	withLocations := slices.Contains(fields, "country") || slices.Contains(fields, "city")
+
+	rowGroupSize := defaultExportRowGroupSize
+	if value := c.Query("row_group_size"); value != "" {
+		rowGroupSize, err = strconv.Atoi(value)
+		if err != nil || rowGroupSize < 1 || rowGroupSize > maxExportRowGroupSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": fmt.Sprintf("row_group_size must be between 1 and %d", maxExportRowGroupSize)})
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	var contentType, ext string
+	var newWriter func(w io.Writer) (userExportWriter, error)
+	switch format {
+	case "csv":
+		contentType, ext = "text/csv; charset=utf-8", "csv"
+		newWriter = func(w io.Writer) (userExportWriter, error) { return newCSVExportWriter(w, fields) }
+	case "ndjson":
+		contentType, ext = "application/x-ndjson", "ndjson"
+		newWriter = func(w io.Writer) (userExportWriter, error) {
+			return &ndjsonExportWriter{enc: json.NewEncoder(w), fields: fields}, nil
+		}
+	case "columnar":
+		contentType, ext = "application/json", "json"
+		newWriter = func(w io.Writer) (userExportWriter, error) {
+			return newColumnarExportWriter(w, fields, rowGroupSize)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "format must be csv, ndjson or columnar"})
+		return
+	}
+
+	var locations *helpers.LocationLookup
+	if withLocations {
+		if locations, err = helpers.NewLocationLookup(h.HotelServiceClient); err != nil {
+			logrus.WithError(err).Error("failed to load locations for export")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load locations"})
+			return
+		}
+	}
+
+	// headers and the writer are set up lazily: a query error before the first row is still a JSON 500
+	var writer userExportWriter
+	rows := 0
+	err = h.service.StreamUsers(c.Request.Context(), filter, fields, func(user models.User) error {
+		if writer == nil {
+			started, err := h.startExport(c, contentType, ext, newWriter)
+			if err != nil {
+				return err
+			}
+			writer = started
+		}
+
+		response := helpers.ToUserResponse(user)
+		if locations != nil {
+			locations.Apply(&response)
+		}
+		if err := writer.Write(exportRow(response, fields)); err != nil {
+			return err
+		}
+
+		if rows++; rows%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err == nil && writer == nil {
+		// no rows: still a valid (empty) file
+		writer, err = h.startExport(c, contentType, ext, newWriter)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if writer == nil {
+			logrus.WithError(err).Error("failed to export users")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export users"})
+			return
+		}
+		// the status is already sent, the truncated body is all the client gets
+		logrus.WithError(err).WithField("rows", rows).Error("user export aborted")
+		return
+	}
+
+	c.Writer.Flush()
+}
+
+func (h *UserHandler) startExport(c *gin.Context, contentType, ext string, newWriter func(io.Writer) (userExportWriter, error)) (userExportWriter, error) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().UTC().Format("20060102"), ext))
+	c.Status(http.StatusOK)
+	return newWriter(c.Writer)
+}
+
+// exportRow — values of the fields in order, nil for NULL
+func exportRow(response dto.UserResponse, fields []string) []any {
+	row := make([]any, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "id":
+			row[i] = response.ID.String()
+		case "first_name":
+			row[i] = response.FirstName
+		case "last_name":
+			row[i] = response.LastName
+		case "email":
+			row[i] = response.Email
+		case "role":
+			row[i] = response.Role
+		case "birth":
+			row[i] = stringOrNil(response.Birth)
+		case "gender":
+			row[i] = stringOrNil(response.Gender)
+		case "country_id":
+			if response.CountryID != nil {
+				row[i] = response.CountryID.String()
+			}
+		case "country":
+			row[i] = stringOrNil(response.Country)
+		case "city_id":
+			if response.CityID != nil {
+				row[i] = response.CityID.String()
+			}
+		case "city":
+			row[i] = stringOrNil(response.City)
+		case "created_at":
+			row[i] = response.CreatedAt
+		case "updated_at":
+			row[i] = response.UpdatedAt
+		}
+	}
+	return row
+}
+
+func stringOrNil(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// csvExportWriter — header row with field names, NULL as empty cell
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer, fields []string) (*csvExportWriter, error) {
+	writer := &csvExportWriter{w: csv.NewWriter(w)}
+	return writer, writer.w.Write(fields)
+}
+
+func (e *csvExportWriter) Write(row []any) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		if value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExportWriter — one JSON object per line
+type ndjsonExportWriter struct {
+	enc    *json.Encoder
+	fields []string
+}
+
+func (e *ndjsonExportWriter) Write(row []any) error {
+	object := make(map[string]any, len(row))
+	for i, field := range e.fields {
+		object[field] = row[i]
+	}
+	return e.enc.Encode(object)
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// columnarExportWriter — Parquet-like layout in JSON: rows are buffered into row groups,
+// every group holds one array per column
+//
+//	{"columns":["id",...],"row_groups":[{"num_rows":2,"columns":{"id":["...","..."],...}},...]}
+type columnarExportWriter struct {
+	w       io.Writer
+	fields  []string
+	size    int
+	group   [][]any // column -> values
+	rows    int
+	written int // row groups already written
+}
+
+func newColumnarExportWriter(w io.Writer, fields []string, size int) (*columnarExportWriter, error) {
+	header, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, `{"columns":%s,"row_groups":[`, header); err != nil {
+		return nil, err
+	}
+
+	writer := &columnarExportWriter{w: w, fields: fields, size: size}
+	writer.reset()
+	return writer, nil
+}
+
+func (e *columnarExportWriter) reset() {
+	e.group = make([][]any, len(e.fields))
+	for i := range e.group {
+		e.group[i] = make([]any, 0, e.size)
+	}
+	e.rows = 0
+}
+
+func (e *columnarExportWriter) Write(row []any) error {
+	for i, value := range row {
+		e.group[i] = append(e.group[i], value)
+	}
+	if e.rows++; e.rows == e.size {
+		return e.flushGroup()
+	}
+	return nil
+}
+
+func (e *columnarExportWriter) flushGroup() error {
+	columns := make(map[string][]any, len(e.fields))
+	for i, field := range e.fields {
+		columns[field] = e.group[i]
+	}
+	group, err := json.Marshal(map[string]any{"num_rows": e.rows, "columns": columns})
+	if err != nil {
+		return err
+	}
+
+	if e.written > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	if _, err := e.w.Write(group); err != nil {
+		return err
+	}
+	e.written++
+	e.reset()
+	return nil
+}
+
+func (e *columnarExportWriter) Close() error {
+	if e.rows > 0 {
+		if err := e.flushGroup(); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(e.w, "]}")
+	return err
+}
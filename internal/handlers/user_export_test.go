@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+func setupExportRouter(mockService *MockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	handler := &UserHandler{service: mockService}
+	router.GET("/users/export", handler.ExportUsersHandler)
+	return router
+}
+
+func exportUsers() []models.User {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	gender := "female"
+	return []models.User{
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), FirstName: "John", Email: "john@example.com", CreatedAt: created},
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), FirstName: "Jane", Email: "jane@example.com", Gender: &gender, CreatedAt: created},
+	}
+}
+
+func TestExportUsersHandler_CSV(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExportRouter(mockService)
+
+	role := "user"
+	fields := []string{"id", "first_name", "gender"}
+	mockService.On("StreamUsers", models.UserFilter{Role: &role}, fields).Return(exportUsers(), nil)
+
+	req, _ := http.NewRequest("GET", "/users/export?role=user&fields=id,first_name,gender", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+	assert.Equal(t, "id,first_name,gender\n"+
+		"00000000-0000-0000-0000-000000000001,John,\n"+
+		"00000000-0000-0000-0000-000000000002,Jane,female\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestExportUsersHandler_NDJSON(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExportRouter(mockService)
+
+	mockService.On("StreamUsers", models.UserFilter{}, exportFields).Return(exportUsers(), nil)
+
+	req, _ := http.NewRequest("GET", "/users/export?format=ndjson", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var row map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	assert.Equal(t, "jane@example.com", row["email"])
+	assert.Equal(t, "female", row["gender"])
+	assert.Nil(t, row["country_id"])
+}
+
+func TestExportUsersHandler_ColumnarRowGroups(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExportRouter(mockService)
+
+	users := append(exportUsers(), models.User{ID: uuid.New(), FirstName: "Jim"})
+	mockService.On("StreamUsers", models.UserFilter{}, []string{"first_name"}).Return(users, nil)
+
+	req, _ := http.NewRequest("GET", "/users/export?format=columnar&fields=first_name&row_group_size=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Columns   []string `json:"columns"`
+		RowGroups []struct {
+			NumRows int                 `json:"num_rows"`
+			Columns map[string][]string `json:"columns"`
+		} `json:"row_groups"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"first_name"}, resp.Columns)
+	assert.Len(t, resp.RowGroups, 2)
+	assert.Equal(t, []string{"John", "Jane"}, resp.RowGroups[0].Columns["first_name"])
+	assert.Equal(t, 1, resp.RowGroups[1].NumRows)
+}
+
+func TestExportUsersHandler_EmptyAndErrors(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupExportRouter(mockService)
+
+	mockService.On("StreamUsers", models.UserFilter{}, []string{"email"}).Return([]models.User{}, nil)
+	mockService.On("StreamUsers", models.UserFilter{}, []string{"id"}).Return([]models.User{}, errors.New("db down"))
+
+	// пустая выгрузка — валидный файл с одним заголовком
+	req, _ := http.NewRequest("GET", "/users/export?fields=email", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "email\n", w.Body.String())
+
+	// ошибка до первой строки — обычный JSON 500
+	req, _ = http.NewRequest("GET", "/users/export?fields=id", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to export users")
+
+	for _, query := range []string{"format=xml", "fields=password", "expand=profile", "row_group_size=0"} {
+		req, _ = http.NewRequest("GET", "/users/export?"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
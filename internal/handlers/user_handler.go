@@ -3,18 +3,20 @@ package handlers
 import (
 	"database/sql"
 	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"os"
 
-	//"strconv"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
-	"github.com/vitalii-q/selena-users-service/internal/helpers"
 	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
 	"github.com/vitalii-q/selena-users-service/internal/services"
 	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
 	"github.com/vitalii-q/selena-users-service/internal/metrics"
@@ -48,6 +50,17 @@ func (h *UserHandler) CreateUserHandler(c *gin.Context) {
 		return
 	}
 
+	// the role goes into access tokens: public sign-up always creates a user, only admins pick another role
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	if user.Role != "user" {
+		if claims, ok := middleware.GetClaims(c); !ok || claims.Role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can assign a role"})
+			return
+		}
+	}
+
 	// Логируем значение SSLMODE из env
 	sslMode := os.Getenv("USERS_POSTGRES_DB_SSLMODE")
 	logrus.Infof("SSL mode from env: %s", sslMode)
@@ -81,26 +94,61 @@ func (h *UserHandler) GetUserHandler(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.GetUser(id)
+	expanders, err := parseExpand(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+	if !privateExpandAllowed(c, expanders, []uuid.UUID{id}) {
+		return
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+
+	var user models.User
+	if fields != nil {
+		user, err = h.service.GetUserFields(id, fields)
+	} else {
+		user, err = h.service.GetUser(id)
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// enrichment через LocationsClient
-	enrichedUsers, err := helpers.EnrichUsers(
-		[]models.User{user},
-		h.HotelServiceClient,
-	)
+	// expanded data (profile, locations, identities, stats) is not covered by users.version,
+	// such responses get no ETag and are never answered with 304
+	expanders = withFieldExpanders(fields, expanders)
+	if len(expanders) == 0 {
+		etag := userFieldsETag(user.Version, fields)
+		c.Header("ETag", etag)
+		if ifNoneMatchHit(c, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	responses, err := h.expandUsers([]models.User{user}, expanders)
 	if err != nil {
-		logrus.WithError(err).Error("failed to enrich user with locations")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to enrich user with locations",
-		})
+		logrus.WithError(err).Error("failed to expand user")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to expand user"})
+		return
+	}
+
+	if fields != nil {
+		sparse, err := sparseResponses(responses, fields, expanders)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, sparse[0])
 		return
 	}
 
-	c.JSON(http.StatusOK, enrichedUsers[0])
+	c.JSON(http.StatusOK, responses[0])
 }
 
 // UpdateUserHandler - обработчик для обновления данных пользователя
@@ -158,20 +206,81 @@ func (h *UserHandler) UpdateUserHandler(c *gin.Context) {
 		}
 	}
 
+	ifMatch, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
 	// Обновляем пользователя
-	updatedUser, err = h.service.UpdateUser(id, updatedUser)
+	updatedUser, err = h.service.UpdateUser(id, updatedUser, ifMatch)
 	if err != nil {
 		if err.Error() == "user not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
 		}
+		if errors.Is(err, services.ErrPreconditionFailed) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", userETag(updatedUser.Version))
 	c.JSON(http.StatusOK, updatedUser)
 }
 
+// PatchUserHandler - частичное обновление пользователя (RFC 7396 JSON Merge Patch)
+// PATCH /api/v1/users/:id
+func (h *UserHandler) PatchUserHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	changes, err := parseUserMergePatch(body, id, h.validator)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	ifMatch, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.PatchUser(id, changes, ifMatch)
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("ETag", userETag(user.Version))
+	c.JSON(http.StatusOK, user)
+}
+
 // DeleteUserHandler - обработчик для удаления пользователя
 func (h *UserHandler) DeleteUserHandler(c *gin.Context) {
 	idStr := c.Param("id")
@@ -183,11 +292,18 @@ func (h *UserHandler) DeleteUserHandler(c *gin.Context) {
 		return
 	}
 
+	ifMatch, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
 	// Вызываем сервис для удаления пользователя
-	err = h.service.DeleteUser(id)
+	err = h.service.DeleteUser(id, ifMatch)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) || err.Error() == "user not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		} else if errors.Is(err, services.ErrPreconditionFailed) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -197,31 +313,124 @@ func (h *UserHandler) DeleteUserHandler(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
-// GetUsersHandler — get a list of all users with country/city names
-func (h *UserHandler) GetUsersHandler(c *gin.Context) {
-	// read query parameter
-	expand := c.Query("expand")
+// RestoreUserHandler - undoes a soft delete
+// POST /api/v1/users/:id/restore
+func (h *UserHandler) RestoreUserHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
 
-	if expand == "locations" {
-		usersWithLocations, err := h.service.(*services.UserService).GetAllUsersWithLocations()
-		if err != nil {
-			logrus.WithError(err).Error("failed to get users with locations")
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to fetch users with locations",
+	user, err := h.service.RestoreUser(id)
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrUserNotDeleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"details": "another active account uses this email, change it before restoring",
 			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("ETag", userETag(user.Version))
+	c.JSON(http.StatusOK, user)
+}
+
+// eraseUserRequest - optional body of the erase request
+type eraseUserRequest struct {
+	Reason string `json:"reason"` // e.g. data-subject request ticket, stored in the audit log
+}
+
+// EraseUserHandler - GDPR right to erasure: irreversibly anonymizes the user's PII
+// POST /api/v1/users/:id/erase
+func (h *UserHandler) EraseUserHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	var req eraseUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 			return
 		}
+	}
 
-		c.JSON(http.StatusOK, gin.H{
-			"users": usersWithLocations,
-			"count": len(usersWithLocations),
-		})
+	err = h.service.EraseUser(id, requestAudit(c, req.Reason))
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrUserAlreadyErased):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("failed to erase user")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to erase user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "status": "erased"})
+}
+
+// requestAudit — audit data of the current request: acting user, client IP, request ID and
+// the optional reason; the service fills action and target
+func requestAudit(c *gin.Context, reason string) models.AuditLog {
+	audit := models.AuditLog{
+		IPAddress: c.ClientIP(),
+		Metadata:  map[string]any{},
+	}
+	if claims, ok := middleware.GetClaims(c); ok {
+		if actorID, err := uuid.Parse(claims.UserID); err == nil {
+			audit.ActorID = &actorID
+		}
+	}
+	if reason != "" {
+		audit.Metadata["reason"] = reason
+	}
+	if requestID, ok := c.Get("request_id"); ok {
+		audit.Metadata["request_id"] = requestID
+	}
+	return audit
+}
+
+// GetUsersHandler — keyset-paginated list of users with filters and sort
+// GET /api/v1/users?limit=&cursor=&sort=&role=&gender=&country_id=&city_id=&created_from=&created_to=&age_min=&age_max=
+// admins may add ?include_deleted=true or ?only_deleted=true
+// ?expand=locations,profile,identities,stats adds related data (see userExpanders, profile/identities/stats only for own data or admins),
+// ?fields=id,first_name,city returns only those fields (and only their columns are read)
+func (h *UserHandler) GetUsersHandler(c *gin.Context) {
+	params, err := parseUserListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+	if !deletedScopeAllowed(c, params.Filter) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "include_deleted and only_deleted require admin role"})
+		return
+	}
+	expanders, err := parseExpand(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
 		return
 	}
 
-	// Get users from service
-	users, err := h.service.GetAllUsers()
+	page, err := h.service.ListUsers(params)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) || errors.Is(err, services.ErrInvalidSort) || errors.Is(err, services.ErrInvalidField) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logrus.WithError(err).Error("failed to get users")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to fetch users",
@@ -229,9 +438,78 @@ func (h *UserHandler) GetUsersHandler(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uuid.UUID, 0, len(page.Users))
+	for _, user := range page.Users {
+		ids = append(ids, user.ID)
+	}
+	if !privateExpandAllowed(c, expanders, ids) {
+		return
+	}
+
+	var nextCursor *string
+	if page.NextCursor != "" {
+		nextCursor = &page.NextCursor
+	}
+
+	if len(expanders) > 0 || params.Fields != nil {
+		expanders = withFieldExpanders(params.Fields, expanders)
+		responses, err := h.expandUsers(page.Users, expanders)
+		if err != nil {
+			logrus.WithError(err).Error("failed to expand users")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to expand users"})
+			return
+		}
+
+		var users any = responses
+		if params.Fields != nil {
+			if users, err = sparseResponses(responses, params.Fields, expanders); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"users":       users,
+			"count":       len(responses),
+			"next_cursor": nextCursor,
+		})
+		return
+	}
+
 	// Return users
 	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"count": len(users),
+		"users":       page.Users,
+		"count":       len(page.Users),
+		"next_cursor": nextCursor,
+	})
+}
+
+// SearchUsersHandler — fuzzy search by partial name or email, best matches first (admin only)
+// GET /api/v1/users/search?q=&limit=
+func (h *UserHandler) SearchUsersHandler(c *gin.Context) {
+	limit := services.DefaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > services.MaxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.service.SearchUsers(c.Query("q"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrSearchQueryTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.WithError(err).Error("failed to search users")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": results,
+		"count": len(results),
 	})
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
 	"github.com/vitalii-q/selena-users-service/internal/services"
 	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
 	"github.com/vitalii-q/selena-users-service/internal/utils"
@@ -45,14 +47,83 @@ func (m *MockUserService) GetUser(id uuid.UUID) (models.User, error) {
 }
 
 // Метод для обновления пользователя
-func (m *MockUserService) UpdateUser(id uuid.UUID, user models.User) (models.User, error) {
-	args := m.Called(id, user)
+func (m *MockUserService) UpdateUser(id uuid.UUID, user models.User, ifMatch []int) (models.User, error) {
+	args := m.Called(id, user, ifMatch)
 	return args.Get(0).(models.User), args.Error(1)
 }
 
 // Метод для удаления пользователя
-func (m *MockUserService) DeleteUser(id uuid.UUID) error {
-	return m.Called(id).Error(0)
+func (m *MockUserService) DeleteUser(id uuid.UUID, ifMatch []int) error {
+	return m.Called(id, ifMatch).Error(0)
+}
+
+func (m *MockUserService) RestoreUser(id uuid.UUID) (models.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetProfile(userID uuid.UUID) (models.UserProfile, error) {
+	args := m.Called(userID)
+	return args.Get(0).(models.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) GetProfiles(userIDs []uuid.UUID) (map[uuid.UUID]models.UserProfile, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[uuid.UUID]models.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) StreamUsers(ctx context.Context, filter models.UserFilter, fields []string, fn func(models.User) error) error {
+	args := m.Called(filter, fields)
+	for _, user := range args.Get(0).([]models.User) {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserService) BulkUpdateUsers(ctx context.Context, selector models.BulkUserSelector, changes models.BulkUserChanges, audit models.AuditLog) (models.BulkUserReport, error) {
+	args := m.Called(selector, changes, audit)
+	return args.Get(0).(models.BulkUserReport), args.Error(1)
+}
+
+func (m *MockUserService) BulkDeleteUsers(ctx context.Context, selector models.BulkUserSelector, audit models.AuditLog) (models.BulkUserReport, error) {
+	args := m.Called(selector, audit)
+	return args.Get(0).(models.BulkUserReport), args.Error(1)
+}
+
+func (m *MockUserService) GetUsersByIDs(ids []uuid.UUID, fields []string) ([]models.User, error) {
+	args := m.Called(ids, fields)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUserFields(id uuid.UUID, fields []string) (models.User, error) {
+	args := m.Called(id, fields)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetIdentities(userIDs []uuid.UUID) (map[uuid.UUID][]models.UserIdentity, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[uuid.UUID][]models.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserService) GetStats(userIDs []uuid.UUID) (map[uuid.UUID]models.UserStats, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[uuid.UUID]models.UserStats), args.Error(1)
+}
+
+func (m *MockUserService) ReplaceProfile(userID uuid.UUID, profile models.UserProfile) (models.UserProfile, error) {
+	args := m.Called(userID, profile)
+	return args.Get(0).(models.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) PatchProfile(userID uuid.UUID, changes map[string]any) (models.UserProfile, error) {
+	args := m.Called(userID, changes)
+	return args.Get(0).(models.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) EraseUser(id uuid.UUID, audit models.AuditLog) error {
+	return m.Called(id, audit).Error(0)
 }
 
 func setupRouter(handler *UserHandler) *gin.Engine {
@@ -71,7 +142,7 @@ func TestCreateUserHandler(t *testing.T) {
 	hotelClient := external_services.NewHotelServiceClient()
 	locationsClient := external_services.NewHotelServiceClient()
 	
-	userService := services.NewUserService(mockDB, passwordHasher, hotelClient)
+	userService := services.NewUserService(mockDB, passwordHasher, hotelClient, nil)
 	userHandler := NewUserHandler(userService, locationsClient)
 
 	router := setupRouter(userHandler)
@@ -107,6 +178,36 @@ func TestCreateUserHandler(t *testing.T) {
 	mockDB.ExpectationsWereMet()
 }
 
+func TestCreateUserHandler_RoleIsNotSelfAssigned(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	userService := services.NewUserService(mockDB, &utils.FixedSaltHasher{}, nil, nil)
+	router := setupRouter(NewUserHandler(userService, nil))
+
+	// анонимная регистрация с ролью admin отклоняется
+	body := `{"first_name": "Eve", "last_name": "Doe", "email": "eve@example.com", "password": "secret123", "role": "admin"}`
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// без роли создаётся обычный пользователь
+	mockDB.ExpectQuery(`INSERT INTO users`).
+		WithArgs("Eve", "Doe", "eve@example.com", pgxmock.AnyArg(), "user").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(uuid.New(), time.Now(), time.Now()))
+
+	body = `{"first_name": "Eve", "last_name": "Doe", "email": "eve@example.com", "password": "secret123"}`
+	req, _ = http.NewRequest("POST", "/users", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
 func TestCreateUserHandler_InvalidJSON(t *testing.T) {
 	mockDB, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -170,7 +271,7 @@ func TestCreateUserHandler_DBError(t *testing.T) {
 
 	passwordHasher := &utils.FixedSaltHasher{} // добавили хешер
 	hotelClient := external_services.NewHotelServiceClient()
-	userService := services.NewUserService(mockDB, passwordHasher, hotelClient) // передаем его в сервис
+	userService := services.NewUserService(mockDB, passwordHasher, hotelClient, nil) // передаем его в сервис
 	locationsClient := external_services.NewHotelServiceClient()
 
 	userHandler := NewUserHandler(userService, locationsClient)
@@ -271,7 +372,7 @@ func TestUpdateUserHandler_Success(t *testing.T) {
     }
 
     // Мокируем обновление
-    mockService.On("UpdateUser", userID, updatedUser).Return(updatedUser, nil)
+    mockService.On("UpdateUser", userID, updatedUser, []int(nil)).Return(updatedUser, nil)
 
     // Формируем запрос с обновленными данными
     body, _ := json.Marshal(updatedUser)
@@ -327,7 +428,7 @@ func TestUpdateUserHandler_UserNotFound(t *testing.T) {
 	router.PUT("/users/:id", handler.UpdateUserHandler)
 
 	userID := uuid.New()
-	mockService.On("UpdateUser", userID, mock.Anything).Return(models.User{}, errors.New("user not found"))
+	mockService.On("UpdateUser", userID, mock.Anything, mock.Anything).Return(models.User{}, errors.New("user not found"))
 
 	body, _ := json.Marshal(models.User{ID: userID})
 	req, _ := http.NewRequest("PUT", "/users/"+userID.String(), bytes.NewBuffer(body))
@@ -347,7 +448,7 @@ func TestDeleteUserHandler_Success(t *testing.T) {
 	router.DELETE("/users/:id", handler.DeleteUserHandler)
 
 	userID := uuid.New()
-	mockService.On("DeleteUser", userID).Return(nil)
+	mockService.On("DeleteUser", userID, []int(nil)).Return(nil)
 
 	req, _ := http.NewRequest("DELETE", "/users/"+userID.String(), nil)
 	w := httptest.NewRecorder()
@@ -378,7 +479,7 @@ func TestDeleteUserHandler_UserNotFound(t *testing.T) {
 	router.DELETE("/users/:id", handler.DeleteUserHandler)
 
 	userID := uuid.New()
-	mockService.On("DeleteUser", userID).Return(errors.New("user not found"))
+	mockService.On("DeleteUser", userID, []int(nil)).Return(errors.New("user not found"))
 
 	req, _ := http.NewRequest("DELETE", "/users/"+userID.String(), nil)
 	w := httptest.NewRecorder()
@@ -391,4 +492,300 @@ func TestDeleteUserHandler_UserNotFound(t *testing.T) {
 func (m *MockUserService) GetAllUsers() ([]models.User, error) {
 	args := m.Called()
 	return args.Get(0).([]models.User), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockUserService) ListUsers(params models.UserListParams) (models.UserPage, error) {
+	args := m.Called(params)
+	return args.Get(0).(models.UserPage), args.Error(1)
+}
+
+func TestGetUsersHandler_Pagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.GET("/users", handler.GetUsersHandler)
+
+	role := "user"
+	expectedParams := models.UserListParams{
+		Filter: models.UserFilter{Role: &role},
+		Limit:  10,
+		Cursor: "abc",
+		Sort:   "email",
+	}
+	users := []models.User{{ID: uuid.New(), FirstName: "John"}}
+	mockService.On("ListUsers", expectedParams).Return(models.UserPage{Users: users, NextCursor: "next"}, nil)
+
+	req, _ := http.NewRequest("GET", "/users?limit=10&cursor=abc&sort=email&role=user", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "next", resp["next_cursor"])
+	assert.Equal(t, float64(1), resp["count"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUsersHandler_InvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	handler := &UserHandler{service: new(MockUserService)}
+	router.GET("/users", handler.GetUsersHandler)
+
+	for _, query := range []string{"limit=0", "limit=1000", "country_id=bad", "age_min=40&age_max=20", "created_from=yesterday"} {
+		req, _ := http.NewRequest("GET", "/users?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func (m *MockUserService) SearchUsers(q string, limit int) ([]models.UserSearchResult, error) {
+	args := m.Called(q, limit)
+	return args.Get(0).([]models.UserSearchResult), args.Error(1)
+}
+
+func TestSearchUsersHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.GET("/users/search", handler.SearchUsersHandler)
+	router.GET("/users/:id", handler.GetUserHandler)
+
+	results := []models.UserSearchResult{{User: models.User{ID: uuid.New(), FirstName: "Johanna"}, Score: 0.8}}
+	mockService.On("SearchUsers", "joh", 5).Return(results, nil)
+
+	req, _ := http.NewRequest("GET", "/users/search?q=joh&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"score":0.8`)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchUsersHandler_QueryTooShort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.GET("/users/search", handler.SearchUsersHandler)
+
+	mockService.On("SearchUsers", "j", services.DefaultSearchLimit).Return([]models.UserSearchResult(nil), services.ErrSearchQueryTooShort)
+
+	req, _ := http.NewRequest("GET", "/users/search?q=j", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func (m *MockUserService) PatchUser(id uuid.UUID, changes map[string]any, ifMatch []int) (models.User, error) {
+	args := m.Called(id, changes, ifMatch)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func TestPatchUserHandler_MergePatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService, validator: validator.New()}
+	router.PATCH("/users/:id", handler.PatchUserHandler)
+
+	userID := uuid.New()
+	expectedChanges := map[string]any{
+		"first_name": "Updated",
+		"gender":     nil, // явный null очищает поле
+	}
+	mockService.On("PatchUser", userID, expectedChanges, []int(nil)).Return(models.User{ID: userID, FirstName: "Updated"}, nil)
+
+	body := []byte(`{"first_name": "Updated", "gender": null}`)
+	req, _ := http.NewRequest("PATCH", "/users/"+userID.String(), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchUserHandler_InvalidPatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	handler := &UserHandler{service: new(MockUserService), validator: validator.New()}
+	router.PATCH("/users/:id", handler.PatchUserHandler)
+
+	userID := uuid.New()
+	for _, body := range []string{
+		`[]`,
+		`{"email": null}`,
+		`{"email": "not-an-email"}`,
+		`{"role": "admin"}`,
+		`{"birth": "01.02.1990"}`,
+		`{"nickname": "jd"}`,
+	} {
+		req, _ := http.NewRequest("PATCH", "/users/"+userID.String(), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	req, _ := http.NewRequest("PATCH", "/users/"+userID.String(), bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestGetUserHandler_NotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.GET("/users/:id", handler.GetUserHandler)
+
+	userID := uuid.New()
+	mockService.On("GetUser", userID).Return(models.User{ID: userID, Version: 4}, nil)
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String(), nil)
+	req.Header.Set("If-None-Match", `W/"4"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchUserHandler_IfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService, validator: validator.New()}
+	router.PATCH("/users/:id", handler.PatchUserHandler)
+
+	userID := uuid.New()
+	changes := map[string]any{"first_name": "Updated"}
+	mockService.On("PatchUser", userID, changes, []int{3}).Return(models.User{ID: userID, FirstName: "Updated", Version: 4}, nil).Once()
+	mockService.On("PatchUser", userID, changes, []int{3}).Return(models.User{}, services.ErrPreconditionFailed).Once()
+
+	send := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/users/"+userID.String(), bytes.NewBufferString(`{"first_name": "Updated"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	// второй клиент с устаревшей версией
+	w = send()
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteUserHandler_IfMatchRequired(t *testing.T) {
+	t.Setenv("REQUIRE_IF_MATCH", "true")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.DELETE("/users/:id", handler.DeleteUserHandler)
+
+	req, _ := http.NewRequest("DELETE", "/users/"+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockService.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
+
+func TestGetUsersHandler_DeletedScopeAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.GET("/users", handler.GetUsersHandler)
+
+	mockService.On("ListUsers", models.UserListParams{Filter: models.UserFilter{OnlyDeleted: true}}).
+		Return(models.UserPage{Users: []models.User{}}, nil)
+
+	for role, expected := range map[string]int{"user": http.StatusForbidden, "admin": http.StatusOK} {
+		req, _ := http.NewRequest("GET", "/users?only_deleted=true", nil)
+		req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: role}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expected, w.Code, role)
+	}
+	mockService.AssertNumberOfCalls(t, "ListUsers", 1)
+}
+
+func TestRestoreUserHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.POST("/users/:id/restore", handler.RestoreUserHandler)
+
+	restored, conflicting, active := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("RestoreUser", restored).Return(models.User{ID: restored, Version: 5}, nil)
+	mockService.On("RestoreUser", conflicting).Return(models.User{}, services.ErrEmailTaken)
+	mockService.On("RestoreUser", active).Return(models.User{}, services.ErrUserNotDeleted)
+
+	for id, expected := range map[uuid.UUID]int{
+		restored:    http.StatusOK,
+		conflicting: http.StatusConflict,
+		active:      http.StatusConflict,
+	} {
+		req, _ := http.NewRequest("POST", "/users/"+id.String()+"/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expected, w.Code)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestEraseUserHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	mockService := new(MockUserService)
+	handler := &UserHandler{service: mockService}
+	router.POST("/users/:id/erase", middleware.RequireRole("admin"), handler.EraseUserHandler)
+
+	adminID, userID := uuid.New(), uuid.New()
+	mockService.On("EraseUser", userID, mock.MatchedBy(func(audit models.AuditLog) bool {
+		return audit.ActorID != nil && *audit.ActorID == adminID && audit.Metadata["reason"] == "DSR-42"
+	})).Return(nil).Once()
+	mockService.On("EraseUser", userID, mock.Anything).Return(services.ErrUserAlreadyErased).Once()
+
+	send := func(role string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/users/"+userID.String()+"/erase", bytes.NewBufferString(`{"reason": "DSR-42"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: adminID.String(), Role: role}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, send("user").Code)
+	assert.Equal(t, http.StatusOK, send("admin").Code)
+	assert.Equal(t, http.StatusConflict, send("admin").Code)
+	mockService.AssertExpectations(t)
+}
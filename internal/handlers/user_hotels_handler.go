@@ -1,30 +1,77 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/services"
 )
 
 type UserHotelsHandler struct {
-	hotelClient *external_services.HotelServiceClient
+	recommendations *services.HotelRecommendationService
 }
 
-func NewUserHotelsHandler(hotelClient *external_services.HotelServiceClient) *UserHotelsHandler {
-	return &UserHotelsHandler{hotelClient: hotelClient}
+func NewUserHotelsHandler(recommendations *services.HotelRecommendationService) *UserHotelsHandler {
+	return &UserHotelsHandler{recommendations: recommendations}
 }
 
-// GET /users/:userId/hotels
+// GetUserHotelsHandler — hotels ranked for the user, each with its score and explanation (owner or admin)
+// GET /users/:id/hotels?strategy=personal|nearby|discover&limit=&offset=
 func (h *UserHotelsHandler) GetUserHotelsHandler(c *gin.Context) {
-	userId := c.Param("id")
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	params := services.HotelRecommendationParams{Strategy: c.Query("strategy"), Limit: services.DefaultRecommendationLimit}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > services.MaxRecommendationLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "invalid limit"})
+			return
+		}
+		params.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "invalid offset"})
+			return
+		}
+		params.Offset = offset
+	}
 
-	hotels, err := h.hotelClient.GetHotels()
+	page, err := h.recommendations.Recommend(c.Request.Context(), id, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrUnknownStrategy):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		case errors.Is(err, services.ErrHotelServiceUnavailable):
+			logrus.WithError(err).Warn("hotel service unavailable")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "hotel service unavailable"})
+		default:
+			logrus.WithError(err).Error("failed to recommend hotels")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recommend hotels"})
+		}
 		return
 	}
 
-	hotels = h.hotelClient.ApplyBusinessLogic(userId, hotels)
-	c.JSON(http.StatusOK, hotels)
+	var nextOffset *int
+	if next := params.Offset + len(page.Hotels); next < page.Total {
+		nextOffset = &next
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":    page.Strategy,
+		"hotels":      page.Hotels,
+		"count":       len(page.Hotels),
+		"total":       page.Total,
+		"next_offset": nextOffset,
+	})
 }
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/metrics"
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// maxImportBodyBytes — upper bound of an import body, MaxImportRows rows fit well below it
+const maxImportBodyBytes = 32 << 20
+
+// UserImportHandler handles bulk user imports
+type UserImportHandler struct {
+	imports *services.UserImportService
+}
+
+// NewUserImportHandler creates new handler
+func NewUserImportHandler(imports *services.UserImportService) *UserImportHandler {
+	return &UserImportHandler{imports: imports}
+}
+
+// Import creates users from a CSV or NDJSON body and returns a per-row report (admin only)
+// POST /api/v1/users/import?dry_run=true
+// format comes from ?format=csv|ndjson or Content-Type text/csv / application/x-ndjson
+func (h *UserImportHandler) Import(c *gin.Context) {
+	format := importFormat(c)
+	if format == "" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": services.ErrInvalidImportFormat.Error()})
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": "invalid dry_run"})
+			return
+		}
+		dryRun = flag
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	report, err := h.imports.Import(c.Request.Context(), body, format, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge), errors.Is(err, services.ErrImportTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrImportTooLarge.Error()})
+		case errors.Is(err, services.ErrInvalidImportHeader):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("user import failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "user import failed"})
+		}
+		return
+	}
+
+	if !dryRun {
+		metrics.UsersCreatedTotal.Add(float64(report.Created))
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importFormat — ?format= wins over Content-Type, "" when neither names a supported format
+func importFormat(c *gin.Context) string {
+	switch c.Query("format") {
+	case models.ImportFormatCSV, models.ImportFormatNDJSON:
+		return c.Query("format")
+	case "":
+	default:
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return models.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return models.ImportFormatNDJSON
+	}
+	return ""
+}
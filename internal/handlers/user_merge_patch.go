@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// parseUserMergePatch turns an RFC 7396 merge patch into column changes.
+// Absent members are left untouched, explicit null clears nullable columns.
+func parseUserMergePatch(body []byte, id uuid.UUID, v *validator.Validate) (map[string]any, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("patch document must be a JSON object")
+	}
+
+	changes := make(map[string]any, len(patch))
+
+	for field, raw := range patch {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		switch field {
+		case "id":
+			var patchID uuid.UUID
+			if isNull || json.Unmarshal(raw, &patchID) != nil || patchID != id {
+				return nil, fmt.Errorf("cannot update ID")
+			}
+
+		case "first_name", "last_name", "email":
+			if isNull {
+				return nil, fmt.Errorf("%s cannot be null", field)
+			}
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("%s must be a string", field)
+			}
+			rule := "required,min=2"
+			if field == "email" {
+				rule = "required,email"
+			}
+			if err := v.Var(value, rule); err != nil {
+				return nil, fmt.Errorf("invalid %s", field)
+			}
+			changes[field] = value
+
+		case "gender":
+			if isNull {
+				changes[field] = nil
+				continue
+			}
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil || v.Var(value, "required,max=10") != nil {
+				return nil, fmt.Errorf("invalid gender")
+			}
+			changes[field] = value
+
+		case "birth":
+			if isNull {
+				changes[field] = nil
+				continue
+			}
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("birth must be a YYYY-MM-DD string")
+			}
+			birth, err := time.Parse("2006-01-02", value)
+			if err != nil || birth.After(time.Now()) {
+				return nil, fmt.Errorf("invalid birth")
+			}
+			changes[field] = birth
+
+		case "country_id", "city_id":
+			if isNull {
+				changes[field] = nil
+				continue
+			}
+			var value uuid.UUID
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("invalid %s", field)
+			}
+			changes[field] = value
+
+		case "password", "role":
+			return nil, fmt.Errorf("%s cannot be changed with PATCH", field)
+
+		default:
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+	}
+
+	return changes, nil
+}
+
+// profilePatchRules — validation of profile fields in a merge patch
+var profilePatchRules = map[string]string{
+	"phone":      "max=32", // normalized to E.164 by the service
+	"address":    "max=500",
+	"bio":        "max=1000",
+	"avatar_url": "url",
+}
+
+// parseProfileMergePatch turns an RFC 7396 merge patch into profile column changes
+func parseProfileMergePatch(body []byte, v *validator.Validate) (map[string]any, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("patch document must be a JSON object")
+	}
+
+	changes := make(map[string]any, len(patch))
+
+	for field, raw := range patch {
+		rule, ok := profilePatchRules[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			changes[field] = nil
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("%s must be a string", field)
+		}
+		if err := v.Var(value, rule); err != nil {
+			return nil, fmt.Errorf("invalid %s", field)
+		}
+		changes[field] = value
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// profileRequest - body of PUT /api/v1/users/:id/profile
+type profileRequest struct {
+	Phone     *string `json:"phone" validate:"omitempty,max=32"` // normalized to E.164 by the service
+	Address   *string `json:"address" validate:"omitempty,max=500"`
+	Bio       *string `json:"bio" validate:"omitempty,max=1000"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,url"`
+}
+
+// GetProfileHandler - profile of the user
+// GET /api/v1/users/:id/profile
+func (h *UserHandler) GetProfileHandler(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetProfile(id)
+	if err != nil {
+		if errors.Is(err, services.ErrProfileNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ReplaceProfileHandler - creates or fully replaces the profile, absent fields are cleared
+// PUT /api/v1/users/:id/profile
+func (h *UserHandler) ReplaceProfileHandler(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req profileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": "Malformed JSON or wrong field types"})
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	profile, err := h.service.ReplaceProfile(id, models.UserProfile{
+		Phone:     req.Phone,
+		Address:   req.Address,
+		Bio:       req.Bio,
+		AvatarURL: req.AvatarURL,
+	})
+	h.respondProfile(c, profile, err)
+}
+
+// PatchProfileHandler - RFC 7396 merge patch of the profile, creates it when missing
+// PATCH /api/v1/users/:id/profile
+func (h *UserHandler) PatchProfileHandler(c *gin.Context) {
+	id, ok := ownerOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	changes, err := parseProfileMergePatch(body, h.validator)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	profile, err := h.service.PatchProfile(id, changes)
+	h.respondProfile(c, profile, err)
+}
+
+func (h *UserHandler) respondProfile(c *gin.Context, profile models.UserProfile, err error) {
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrPhoneTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		default:
+			logrus.WithError(err).Error("failed to save user profile")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func setupProfileRouter(mockService *MockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Authenticate(notRevokedChecker{}))
+	handler := &UserHandler{service: mockService, validator: validator.New()}
+	router.GET("/users", handler.GetUsersHandler)
+	router.GET("/users/:id/profile", handler.GetProfileHandler)
+	router.PUT("/users/:id/profile", handler.ReplaceProfileHandler)
+	router.PATCH("/users/:id/profile", handler.PatchProfileHandler)
+	return router
+}
+
+func TestGetProfileHandler_NotFound(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupProfileRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("GetProfile", userID).Return(models.UserProfile{}, services.ErrProfileNotFound)
+
+	req, _ := http.NewRequest("GET", "/users/"+userID.String()+"/profile", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandlers_OwnerOrAdminOnly(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupProfileRouter(mockService)
+
+	userID := uuid.New()
+	for _, method := range []string{"GET", "PUT", "PATCH"} {
+		// чужой профиль недоступен ни на чтение, ни на запись
+		req, _ := http.NewRequest(method, "/users/"+userID.String()+"/profile", bytes.NewBufferString(`{"bio": "x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "user"}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, method)
+	}
+	mockService.AssertNotCalled(t, "GetProfile")
+	mockService.AssertNotCalled(t, "ReplaceProfile")
+	mockService.AssertNotCalled(t, "PatchProfile")
+}
+
+func TestReplaceProfileHandler(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupProfileRouter(mockService)
+
+	userID := uuid.New()
+	bio := "Loves small hotels"
+	// отсутствующие поля уходят как nil и очищаются
+	mockService.On("ReplaceProfile", userID, models.UserProfile{Bio: &bio}).
+		Return(models.UserProfile{UserID: userID, Bio: &bio}, nil)
+
+	token := bearer(t, utils.TokenClaims{UserID: userID.String(), Role: "user"})
+	req, _ := http.NewRequest("PUT", "/users/"+userID.String()+"/profile", bytes.NewBufferString(`{"bio": "Loves small hotels"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	req, _ = http.NewRequest("PUT", "/users/"+userID.String()+"/profile", bytes.NewBufferString(`{"avatar_url": "not a url"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatchProfileHandler(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupProfileRouter(mockService)
+
+	userID := uuid.New()
+	mockService.On("PatchProfile", userID, map[string]any{"phone": "+4915112345678", "address": nil}).
+		Return(models.UserProfile{}, services.ErrPhoneTaken)
+
+	token := bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "admin"})
+	req, _ := http.NewRequest("PATCH", "/users/"+userID.String()+"/profile", bytes.NewBufferString(`{"phone": "+4915112345678", "address": null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+
+	req, _ = http.NewRequest("PATCH", "/users/"+userID.String()+"/profile", bytes.NewBufferString(`{"user_id": "x"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("Authorization", token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetUsersHandler_ExpandProfile(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupProfileRouter(mockService)
+
+	withProfile, withoutProfile := uuid.New(), uuid.New()
+	bio := "Frequent traveller"
+	mockService.On("ListUsers", models.UserListParams{}).Return(models.UserPage{
+		Users: []models.User{{ID: withProfile}, {ID: withoutProfile}},
+	}, nil)
+	// один батч-запрос на всю страницу
+	mockService.On("GetProfiles", []uuid.UUID{withProfile, withoutProfile}).Return(map[uuid.UUID]models.UserProfile{
+		withProfile: {UserID: withProfile, Bio: &bio},
+	}, nil).Once()
+
+	// профили чужих пользователей — только для админа
+	req, _ := http.NewRequest("GET", "/users?expand=profile", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: withProfile.String(), Role: "user"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("GET", "/users?expand=profile", nil)
+	req.Header.Set("Authorization", bearer(t, utils.TokenClaims{UserID: uuid.NewString(), Role: "admin"}))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Users []map[string]any `json:"users"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Users, 2)
+	assert.Equal(t, bio, resp.Users[0]["profile"].(map[string]any)["bio"])
+	assert.NotContains(t, resp.Users[1], "profile")
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/server/middleware"
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// parseUserFilter reads listing filters from query string:
+// role, gender, country_id, city_id, created_from, created_to, age_min, age_max,
+// include_deleted, only_deleted (callers must allow those for admins only, see deletedScopeAllowed)
+func parseUserFilter(c *gin.Context) (models.UserFilter, error) {
+	return parseUserFilterValues(c.Query)
+}
+
+// parseUserFilterValues - parseUserFilter over any source of parameters, e.g. a filter expression
+// in query string syntax sent in a request body
+func parseUserFilterValues(get func(key string) string) (models.UserFilter, error) {
+	var filter models.UserFilter
+
+	if role := get("role"); role != "" {
+		if role != "admin" && role != "user" {
+			return filter, fmt.Errorf("invalid role: %s", role)
+		}
+		filter.Role = &role
+	}
+
+	if gender := get("gender"); gender != "" {
+		filter.Gender = &gender
+	}
+
+	for param, target := range map[string]**uuid.UUID{
+		"country_id": &filter.CountryID,
+		"city_id":    &filter.CityID,
+	} {
+		if value := get(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*target = &id
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		if value := get(param); value != "" {
+			t, err := parseTimeParam(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC3339 or YYYY-MM-DD", param)
+			}
+			*target = &t
+		}
+	}
+
+	for param, target := range map[string]**int{
+		"age_min": &filter.AgeMin,
+		"age_max": &filter.AgeMax,
+	} {
+		if value := get(param); value != "" {
+			age, err := strconv.Atoi(value)
+			if err != nil || age < 0 || age > 150 {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*target = &age
+		}
+	}
+
+	if filter.AgeMin != nil && filter.AgeMax != nil && *filter.AgeMin > *filter.AgeMax {
+		return filter, fmt.Errorf("age_min must not be greater than age_max")
+	}
+
+	for param, target := range map[string]*bool{
+		"include_deleted": &filter.IncludeDeleted,
+		"only_deleted":    &filter.OnlyDeleted,
+	} {
+		if value := get(param); value != "" {
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*target = flag
+		}
+	}
+
+	return filter, nil
+}
+
+// parseUserListParams reads filters plus limit, cursor, sort and fields
+func parseUserListParams(c *gin.Context) (models.UserListParams, error) {
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		return models.UserListParams{}, err
+	}
+	fields, err := parseFields(c)
+	if err != nil {
+		return models.UserListParams{}, err
+	}
+
+	params := models.UserListParams{
+		Filter: filter,
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+		Fields: fields,
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > services.MaxUsersPageLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", services.MaxUsersPageLimit)
+		}
+		params.Limit = limit
+	}
+
+	return params, nil
+}
+
+// parseFields — ?fields=id,first_name -> sparse fieldset checked against the allow-list, nil when absent
+func parseFields(c *gin.Context) ([]string, error) {
+	var fields []string
+	seen := map[string]bool{}
+	for _, value := range strings.Split(c.Query("fields"), ",") {
+		if value = strings.TrimSpace(value); value != "" && !seen[value] {
+			seen[value] = true
+			fields = append(fields, value)
+		}
+	}
+
+	if err := services.ValidateUserFields(fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// deletedScopeAllowed - soft-deleted users are visible to admins only
+func deletedScopeAllowed(c *gin.Context, filter models.UserFilter) bool {
+	if !filter.IncludeDeleted && !filter.OnlyDeleted {
+		return true
+	}
+	claims, ok := middleware.GetClaims(c)
+	return ok && claims.Role == "admin"
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
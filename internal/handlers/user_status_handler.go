@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/services"
+)
+
+// UserStatusHandler exposes the account status lifecycle to admins
+type UserStatusHandler struct {
+	statuses *services.UserStatusService
+}
+
+// NewUserStatusHandler creates new handler
+func NewUserStatusHandler(statuses *services.UserStatusService) *UserStatusHandler {
+	return &UserStatusHandler{statuses: statuses}
+}
+
+// GetStatus returns the effective account status
+// GET /api/v1/admin/users/:id/status
+func (h *UserStatusHandler) GetStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	status, err := h.statuses.GetStatus(c.Request.Context(), id)
+	if err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ChangeStatus moves the account to another status:
+// pending -> active, active <-> suspended (optionally until a moment), any -> banned
+// PUT /api/v1/admin/users/:id/status {"status": "suspended", "reason": "...", "until": "2026-01-01T00:00:00Z"}
+func (h *UserStatusHandler) ChangeStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID format"})
+		return
+	}
+
+	var req struct {
+		Status string     `json:"status" binding:"required"`
+		Reason string     `json:"reason"`
+		Until  *time.Time `json:"until"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	status, err := h.statuses.ChangeStatus(c.Request.Context(), id, req.Status, req.Reason, req.Until, requestAudit(c, req.Reason))
+	if err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func respondStatusError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrInvalidStatus),
+		errors.Is(err, services.ErrStatusReasonRequired),
+		errors.Is(err, services.ErrInvalidSuspensionEnd):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOwnStatusChange):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logrus.WithError(err).Error("account status operation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process account status"})
+	}
+}
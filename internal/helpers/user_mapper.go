@@ -2,6 +2,7 @@ package helpers
 
 import (
 	//"log"
+	"errors"
 	"time"
 
 	"github.com/vitalii-q/selena-users-service/internal/dto"
@@ -15,73 +16,103 @@ func EnrichUsers(
 	hotelServiceClient *external_services.HotelServiceClient,
 ) ([]dto.UserResponse, error) {
 
-	result := make([]dto.UserResponse, 0, len(users))
+	result := ToUserResponses(users)
+	if err := AttachLocations(result, hotelServiceClient); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AttachLocations заполняет country / city уже собранных ответов (один запрос locations на всех)
+func AttachLocations(
+	responses []dto.UserResponse,
+	hotelServiceClient *external_services.HotelServiceClient,
+) error {
+	lookup, err := NewLocationLookup(hotelServiceClient)
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		lookup.Apply(&responses[i])
+	}
+
+	return nil
+}
+
+// LocationLookup — названия стран и городов по ID, загружается один раз (для потоковой выгрузки тоже)
+type LocationLookup struct {
+	countries map[string]*string
+	cities    map[string]*string
+}
+
+// NewLocationLookup запрашивает locations у HotelService
+func NewLocationLookup(hotelServiceClient *external_services.HotelServiceClient) (*LocationLookup, error) {
+	if hotelServiceClient == nil {
+		return nil, errors.New("hotel service client is not configured")
+	}
 
-	// Получаем locations ОДИН раз
 	countries, err := hotelServiceClient.GetLocations()
 	if err != nil {
 		return nil, err
 	}
 
-	//log.Printf("HotelService returned country: %+v", countries[0])
-
 	// Строим map-ы для O(1) lookup
-	countryMap := make(map[string]*string)
-	cityMap := make(map[string]*string)
-
+	lookup := &LocationLookup{countries: map[string]*string{}, cities: map[string]*string{}}
 	for _, country := range countries {
 		countryName := country.Name // локальная переменная
-		countryMap[country.ID] = &countryName
+		lookup.countries[country.ID] = &countryName
 
 		for _, city := range country.Cities {
 			cityName := city.Name
-			cityMap[city.ID] = &cityName
+			lookup.cities[city.ID] = &cityName
 		}
 	}
 
-	// Основной цикл по пользователям
-	for _, u := range users {
-
-		var countryName *string
-		var cityName *string
-
-		if u.CountryID != nil {
-			if name, ok := countryMap[u.CountryID.String()]; ok {
-				countryName = name
-			}
-		}
-
-		if u.CityID != nil {
-			if name, ok := cityMap[u.CityID.String()]; ok {
-				cityName = name
-			}
-		}
+	return lookup, nil
+}
 
-		var birthStr *string
-		if u.Birth != nil {
-			s := u.Birth.Format("2006-01-02")
-			birthStr = &s
-		}
+// Apply заполняет country / city ответа, null если не найдено
+func (l *LocationLookup) Apply(response *dto.UserResponse) {
+	if id := response.CountryID; id != nil {
+		response.Country = l.countries[id.String()]
+	}
+	if id := response.CityID; id != nil {
+		response.City = l.cities[id.String()]
+	}
+}
 
-		result = append(result, dto.UserResponse{
-			ID:        u.ID,
-			FirstName: u.FirstName,
-			LastName:  u.LastName,
-			Email:     u.Email,
-			Role:      u.Role,
-			Birth:     birthStr,
-			Gender:    u.Gender,
+// ToUserResponse - maps a user to the response DTO without external lookups (country / city stay null)
+func ToUserResponse(u models.User) dto.UserResponse {
+	var birthStr *string
+	if u.Birth != nil {
+		s := u.Birth.Format("2006-01-02")
+		birthStr = &s
+	}
 
-			CountryID: u.CountryID,
-			Country:   countryName, // null если не найдено
+	return dto.UserResponse{
+		ID:        u.ID,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+		Role:      u.Role,
+		Birth:     birthStr,
+		Gender:    u.Gender,
 
-			CityID: u.CityID,
-			City:   cityName, // null если не найдено
+		CountryID: u.CountryID,
+		CityID:    u.CityID,
 
-			CreatedAt: u.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
-		})
+		CreatedAt: u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
 	}
+}
 
-	return result, nil
+// ToUserResponses - ToUserResponse for a list
+func ToUserResponses(users []models.User) []dto.UserResponse {
+	result := make([]dto.UserResponse, 0, len(users))
+	for _, u := range users {
+		result = append(result, ToUserResponse(u))
+	}
+	return result
 }
@@ -30,6 +30,23 @@ var (
 			Help: "Total number of successfully created users",
 		},
 	)
+
+	// Counts soft-deleted users hard-deleted or anonymized by the purge job.
+	UsersPurgedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "users_service_users_purged_total",
+			Help: "Total number of soft-deleted users purged after the retention period",
+		},
+		[]string{"mode"},
+	)
+
+	// Unix time of the last finished (non dry-run) purge.
+	PurgeLastSuccessTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "users_service_purge_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful purge of soft-deleted users",
+		},
+	)
 )
 
 func Register() {
@@ -37,5 +54,7 @@ func Register() {
 		HTTPRequestsTotal,
 		HTTPRequestDuration,
 		UsersCreatedTotal,
+		UsersPurgedTotal,
+		PurgeLastSuccessTimestamp,
 	)
 }
\ No newline at end of file
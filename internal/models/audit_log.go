@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions
+const (
+	AuditActionUserErased        = "user.erased"
+	AuditActionUserRoleChanged   = "user.role_changed"
+	AuditActionUserDeleted       = "user.deleted"
+	AuditActionUserStatusChanged = "user.status_changed"
+
+	AuditActionTravelDocumentRevealed = "travel_document.revealed"
+)
+
+// Audit target types
+const (
+	AuditTargetUser           = "user"
+	AuditTargetTravelDocument = "travel_document"
+)
+
+// AuditLog - entry of the audit log (audit_logs table), never contains PII
+type AuditLog struct {
+	ID         uuid.UUID      `json:"id"`
+	ActorID    *uuid.UUID     `json:"actor_id"` // who performed the action, nil for system jobs
+	Action     string         `json:"action"`
+	TargetType string         `json:"target_type"`
+	TargetID   *uuid.UUID     `json:"target_id"`
+	IPAddress  string         `json:"ip_address,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
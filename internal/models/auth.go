@@ -1,7 +1,11 @@
 // internal/models/auth.go
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 /*type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -19,5 +23,26 @@ type RegisterRequest struct {
 type UserAuth struct {
 	ID           uuid.UUID `json:"id,omitempty"`
 	Email        string    `json:"email"`
+	Role         string    `json:"role"`
 	PasswordHash string    `json:"password_hash"`
 }
+
+// OAuthClient - confidential client (gateway, service) allowed to call OAuth endpoints
+type OAuthClient struct {
+	ClientID         string     `json:"client_id"`
+	ClientSecretHash string     `json:"-"`
+	Name             string     `json:"name"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TokenIntrospection - RFC 7662 introspection response
+type TokenIntrospection struct {
+	Active    bool              `json:"active"`
+	Sub       string            `json:"sub,omitempty"`
+	Role      string            `json:"role,omitempty"`
+	Exp       int64             `json:"exp,omitempty"`
+	Iat       int64             `json:"iat,omitempty"`
+	Jti       string            `json:"jti,omitempty"`
+	TokenType string            `json:"token_type,omitempty"`
+	Act       map[string]string `json:"act,omitempty"` // real actor of an impersonation token
+}
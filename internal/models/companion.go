@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Relationships of a companion to the user
+var CompanionRelationships = []string{"spouse", "partner", "child", "parent", "sibling", "relative", "friend", "colleague", "other"}
+
+// Date - calendar date, JSON "2006-01-02"
+type Date struct {
+	time.Time
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return err
+	}
+	d.Time = parsed
+	return nil
+}
+
+// Companion - saved guest profile a user books rooms for (user_companions table);
+// identity documents of a companion are TravelDocuments with its CompanionID
+type Companion struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	BirthDate    *Date     `json:"birth_date"`
+	Relationship string    `json:"relationship"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Security event types
+const (
+	SecurityEventImpersonationStarted = "impersonation_started"
+	SecurityEventImpersonationStopped = "impersonation_stopped"
+	SecurityEventPasswordChanged      = "password_changed"
+)
+
+// SecurityEvent - entry of the security event log
+type SecurityEvent struct {
+	ID        uuid.UUID      `json:"id"`
+	UserID    *uuid.UUID     `json:"user_id"`  // subject of the event
+	ActorID   *uuid.UUID     `json:"actor_id"` // who performed the action (admin for impersonation)
+	EventType string         `json:"event_type"`
+	IPAddress string         `json:"ip_address,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Room types a traveller may prefer
+const (
+	RoomTypeSingle = "single"
+	RoomTypeDouble = "double"
+	RoomTypeTwin   = "twin"
+	RoomTypeSuite  = "suite"
+	RoomTypeFamily = "family"
+)
+
+// RoomTypes - every accepted room type
+var RoomTypes = []string{RoomTypeSingle, RoomTypeDouble, RoomTypeTwin, RoomTypeSuite, RoomTypeFamily}
+
+// FavoriteHotel - a hotel of hotels-service the user marked as favorite
+type FavoriteHotel struct {
+	HotelID   string    `json:"hotel_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TravelPreferences - what the user looks for in a hotel, nil / empty means no preference
+type TravelPreferences struct {
+	UserID           uuid.UUID   `json:"user_id"`
+	PreferredCityIDs []uuid.UUID `json:"preferred_city_ids"` // cities of hotels-service locations
+	RoomType         *string     `json:"room_type"`
+	BudgetMin        *int        `json:"budget_min"` // per night, whole units of Currency
+	BudgetMax        *int        `json:"budget_max"`
+	Currency         *string     `json:"currency"`             // ISO 4217, required together with a budget
+	UpdatedAt        *time.Time  `json:"updated_at,omitempty"` // nil while nothing was ever set
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity document types
+var DocumentTypes = []string{"passport", "id_card"}
+
+// TravelDocument - passport or ID card of a user or of one of their companions (travel_documents table);
+// the number is stored encrypted and only revealed through the audited reveal endpoint
+type TravelDocument struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	CompanionID *uuid.UUID `json:"companion_id"` // nil: the user's own document
+	Type        string     `json:"type"`
+	Number      string     `json:"number"`  // masked ("*****0T47") unless revealed
+	Country     string     `json:"country"` // ISO 3166-1 alpha-2 of the issuer
+	ExpiresOn   *Date      `json:"expires_on"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
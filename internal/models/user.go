@@ -13,7 +13,7 @@ type User struct {
 	LastName     string     `json:"last_name" validate:"required,min=2"`
 	Email        string     `json:"email" validate:"required,email"`
 	Password     string     `json:"password,omitempty" validate:"required,min=6"`
-	Role         string     `json:"role" validate:"required,oneof=admin user"`
+	Role         string     `json:"role" validate:"required,oneof=admin user"` // "user" when omitted on sign-up, other roles are set by admins
 	Birth        *time.Time `json:"birth,omitempty"`       // nullable
 	Gender  	 *string 	`json:"gender"`                // nullable
 	CountryID 	 *uuid.UUID `json:"country_id"`            // nullable
@@ -21,4 +21,5 @@ type User struct {
 	CreatedAt    time.Time  `json:"created_at,omitempty"`
 	UpdatedAt    time.Time  `json:"updated_at,omitempty"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty"`  // nullable
+	Version      int        `json:"version,omitempty"`     // optimistic locking, exposed as ETag
 }
@@ -0,0 +1,40 @@
+package models
+
+import "github.com/google/uuid"
+
+// Bulk item statuses
+const (
+	BulkItemUpdated   = "updated"
+	BulkItemDeleted   = "deleted"
+	BulkItemUnchanged = "unchanged" // already in the requested state
+	BulkItemNotFound  = "not_found" // unknown or already deleted
+	BulkItemFailed    = "failed"
+)
+
+// BulkUserSelector - users a bulk operation applies to: explicit IDs or a listing filter, never both
+type BulkUserSelector struct {
+	IDs    []uuid.UUID
+	Filter *UserFilter
+}
+
+// BulkUserChanges - fields a bulk update may set, nil = keep
+type BulkUserChanges struct {
+	Role *string `json:"role"`
+}
+
+// BulkUserItem - result for one user of a bulk operation
+type BulkUserItem struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// BulkUserReport - per-item report of a bulk operation, applied in a single transaction
+type BulkUserReport struct {
+	Total     int            `json:"total"`
+	Changed   int            `json:"changed"`
+	Unchanged int            `json:"unchanged"`
+	NotFound  int            `json:"not_found"`
+	Failed    int            `json:"failed"`
+	Items     []BulkUserItem `json:"items"`
+}
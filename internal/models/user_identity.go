@@ -0,0 +1,7 @@
+package models
+
+// UserIdentity - external OAuth identity linked to a user
+type UserIdentity struct {
+	Provider   string `json:"provider"`
+	ProviderID string `json:"provider_id"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// Import formats
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// Import row statuses, in a dry run "created" means the row would be created
+const (
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+	ImportRowFailed  = "failed"
+)
+
+// UserImportRow - result of one input row (line is 1-based, header included for CSV)
+type UserImportRow struct {
+	Line   int        `json:"line"`
+	Email  string     `json:"email,omitempty"`
+	Status string     `json:"status"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// UserImportReport - per-row report of a bulk import
+type UserImportReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Created int             `json:"created"`
+	Skipped int             `json:"skipped"`
+	Failed  int             `json:"failed"`
+	Rows    []UserImportRow `json:"rows"`
+}
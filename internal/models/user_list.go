@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserFilter - filters for the users listing (nil = not applied)
+type UserFilter struct {
+	Role        *string
+	Gender      *string
+	CountryID   *uuid.UUID
+	CityID      *uuid.UUID
+	CreatedFrom *time.Time // inclusive
+	CreatedTo   *time.Time // exclusive
+	AgeMin      *int       // computed from birth
+	AgeMax      *int
+
+	IncludeDeleted bool // active and soft-deleted users, admin only
+	OnlyDeleted    bool // soft-deleted users only, admin only
+}
+
+// UserListParams - keyset pagination + filters + sort for GET /api/v1/users
+type UserListParams struct {
+	Filter UserFilter
+	Limit  int
+	Cursor string // opaque, taken from previous page next_cursor
+	Sort   string // field name, "-" prefix for descending: -created_at, last_name, email, ...
+	Fields []string // sparse fieldset (?fields=), only the columns behind them are selected; all when empty
+}
+
+// UserPage - one page of users
+type UserPage struct {
+	Users      []User
+	NextCursor string // empty on the last page
+}
+
+// UserSearchResult - user found by search with its relevance
+type UserSearchResult struct {
+	User
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Measurement units
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// UserPreferences - effective preferences of a user, unset ones come from DefaultUserPreferences
+type UserPreferences struct {
+	UserID          uuid.UUID  `json:"user_id"`
+	Language        string     `json:"language"` // BCP 47
+	Currency        string     `json:"currency"` // ISO 4217
+	Timezone        string     `json:"timezone"` // IANA
+	Units           string     `json:"units"`
+	MarketingEmails bool       `json:"marketing_emails"`
+	NotifyEmail     bool       `json:"notify_email"`
+	NotifySMS       bool       `json:"notify_sms"`
+	NotifyPush      bool       `json:"notify_push"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"` // nil while nothing was ever set
+}
+
+// DefaultUserPreferences - values of preferences the user has not set;
+// marketing is opt-in, service notifications are opt-out
+var DefaultUserPreferences = UserPreferences{
+	Language:    "en",
+	Currency:    "EUR",
+	Timezone:    "UTC",
+	Units:       UnitsMetric,
+	NotifyEmail: true,
+	NotifyPush:  true,
+}
+
+// UserConsent - state of one opt-in, as exported in the GDPR archive
+type UserConsent struct {
+	Purpose   string     `json:"purpose"`
+	Granted   bool       `json:"granted"`
+	Explicit  bool       `json:"explicit"` // false: the default applies, the user never chose
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
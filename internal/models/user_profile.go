@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserProfile - optional contact details of a user (user_profiles table, one per user)
+type UserProfile struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Phone           *string    `json:"phone"` // E.164
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	Address         *string    `json:"address"`
+	Bio             *string    `json:"bio"`
+	AvatarURL       *string    `json:"avatar_url"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Avatar - uploaded avatar: the image (fits 1024px) and square thumbnails by size in px
+type Avatar struct {
+	URL        string            `json:"avatar_url"`
+	Thumbnails map[string]string `json:"thumbnails"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// UserStats - activity counters of a user (?expand=stats)
+type UserStats struct {
+	LoginCount         int        `json:"login_count"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	SecurityEventCount int        `json:"security_event_count"`
+}
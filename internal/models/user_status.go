@@ -0,0 +1,43 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Account statuses
+const (
+	UserStatusPending   = "pending" // imported with status pending, not activated by an admin yet
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended" // temporarily, until StatusUntil when set
+	UserStatusBanned    = "banned"    // permanently, terminal
+)
+
+// userStatusTransitions - allowed status changes, banned is reachable from any status
+var userStatusTransitions = map[string][]string{
+	UserStatusPending:   {UserStatusActive, UserStatusBanned},
+	UserStatusActive:    {UserStatusSuspended, UserStatusBanned},
+	UserStatusSuspended: {UserStatusActive, UserStatusBanned},
+}
+
+// UserStatus - account status of a user with the reason of the last change
+type UserStatus struct {
+	Status    string     `json:"status"`
+	Reason    string     `json:"reason,omitempty"`
+	Until     *time.Time `json:"until,omitempty"` // end of a suspension, nil = indefinite
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+}
+
+// Effective - the status at the given moment: a suspension that ran out is active again;
+// only accounts effectively active may sign in and use their tokens
+func (s UserStatus) Effective(now time.Time) UserStatus {
+	if s.Status == UserStatusSuspended && s.Until != nil && !s.Until.After(now) {
+		return UserStatus{Status: UserStatusActive, ChangedAt: s.Until}
+	}
+	return s
+}
+
+// CanTransitionUserStatus - whether an account in status from may be moved to status to
+func CanTransitionUserStatus(from, to string) bool {
+	return slices.Contains(userStatusTransitions[from], to)
+}
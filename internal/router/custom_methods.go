@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// customMethods — dispatches custom methods of a collection (POST /users:batchGet).
+// gin 1.10 cannot route an escaped colon, so "/users:method" is registered as the static "/users"
+// followed by a wildcard that captures ":batchGet"; anything else under that wildcard is a 404
+func customMethods(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := strings.CutPrefix(c.Param("method"), ":")
+		handler, known := methods[name]
+		if !ok || !known {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		handler(c)
+	}
+}
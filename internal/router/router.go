@@ -13,18 +13,30 @@ import (
 // SetupRouter initializes Gin router with all routes and middleware
 func SetupRouter(
 	dbPool *pgxpool.Pool,
+	tokenChecker middleware.TokenRevocationChecker,
 	userHandler *handlers.UserHandler,
 	authHandler *handlers.OAuthHandler,
 	userHotelsHandler *handlers.UserHotelsHandler,
 	locationsHandler *handlers.LocationsHandler,
+	impersonationHandler *handlers.ImpersonationHandler,
+	dataExportHandler *handlers.DataExportHandler,
+	phoneVerificationHandler *handlers.PhoneVerificationHandler,
+	avatarHandler *handlers.AvatarHandler,
+	userImportHandler *handlers.UserImportHandler,
+	userStatusHandler *handlers.UserStatusHandler,
+	preferencesHandler *handlers.PreferencesHandler,
+	travelHandler *handlers.TravelHandler,
+	companionHandler *handlers.CompanionHandler,
+	travelDocumentHandler *handlers.TravelDocumentHandler,
 ) *gin.Engine {
 	r := gin.New()
 
 	// --- Middleware ---
-	r.Use(middleware.RequestID())            // add unique request ID
-	r.Use(middleware.Logger())               // logging middleware
-	r.Use(middleware.PrometheusMiddleware()) // Prometheus metrics middleware
-	r.Use(gin.Recovery())                    // panic recovery middleware
+	r.Use(middleware.RequestID())                // add unique request ID
+	r.Use(middleware.Logger())                   // logging middleware
+	r.Use(middleware.PrometheusMiddleware())     // Prometheus metrics middleware
+	r.Use(gin.Recovery())                        // panic recovery middleware
+	r.Use(middleware.Authenticate(tokenChecker)) // parse bearer token if present
 
 	// --- Root & health checks ---
 	r.GET("/", handleRoot)
@@ -37,21 +49,79 @@ func SetupRouter(
 
 	// --- OAuth ---
 	r.POST("/users/oauth2/authenticate", authHandler.Authenticate)
+	r.POST("/oauth2/introspect", authHandler.Introspect)
 
 	// --- API routes ---
 	api := r.Group("/api/v1")
 	{
 		api.POST("/users", userHandler.CreateUserHandler)
-		api.GET("/users/:id", userHandler.GetUserHandler)
-		api.PUT("/users/:id", userHandler.UpdateUserHandler)
-		api.DELETE("/users/:id", userHandler.DeleteUserHandler)
-		api.GET("/users", userHandler.GetUsersHandler)    // add ?expand=locations to get user locations
+		api.GET("/users/search", middleware.RequireRole("admin"), userHandler.SearchUsersHandler) // ?q=partial name or email, matches expose emails
+		api.GET("/users/export", middleware.RequireRole("admin"), middleware.ForbidImpersonation(), userHandler.ExportUsersHandler) // ?format=csv|ndjson|columnar, listing filters
+		api.POST("/users/import", middleware.RequireRole("admin"), middleware.ForbidImpersonation(), userImportHandler.Import) // CSV / NDJSON body, ?dry_run=true
+		api.POST("/users:method", customMethods(map[string]gin.HandlerFunc{
+			"batchGet": userHandler.BatchGetUsersHandler, // {"ids": [...]} up to 100, ?expand= and ?fields= as for the list
+		}))
+		api.GET("/users/:id", userHandler.GetUserHandler) // ?expand= and ?fields= as for the list, expand=profile,identities,stats for the owner or admins
+		api.PUT("/users/:id", middleware.ForbidImpersonation(), userHandler.UpdateUserHandler) // email changes would hand the login to the impersonator
+		api.PATCH("/users/:id", middleware.ForbidImpersonation(), userHandler.PatchUserHandler) // application/merge-patch+json
+		api.DELETE("/users/:id", middleware.ForbidImpersonation(), userHandler.DeleteUserHandler)
+		api.POST("/users/:id/restore", middleware.RequireRole("admin"), middleware.ForbidImpersonation(), userHandler.RestoreUserHandler)
+		api.POST("/users/:id/erase", middleware.RequireRole("admin"), middleware.ForbidImpersonation(), userHandler.EraseUserHandler) // GDPR right to erasure
+		api.GET("/users/:id/profile", middleware.RequireAuth(), userHandler.GetProfileHandler)
+		api.PUT("/users/:id/profile", middleware.RequireAuth(), userHandler.ReplaceProfileHandler)
+		api.PATCH("/users/:id/profile", middleware.RequireAuth(), userHandler.PatchProfileHandler) // application/merge-patch+json
+		api.GET("/users/:id/preferences", middleware.RequireAuth(), preferencesHandler.GetPreferences)
+		api.PATCH("/users/:id/preferences", middleware.RequireAuth(), preferencesHandler.PatchPreferences) // application/merge-patch+json, null resets to the default
+		api.GET("/users/:id/preferences/travel", middleware.RequireAuth(), travelHandler.GetTravelPreferences)
+		api.PUT("/users/:id/preferences/travel", middleware.RequireAuth(), travelHandler.ReplaceTravelPreferences)
+		api.GET("/users/:id/favorites", middleware.RequireAuth(), travelHandler.ListFavorites)
+		api.POST("/users/:id/favorites", middleware.RequireAuth(), travelHandler.AddFavorite) // {"hotel_id"}, checked against hotels-service
+		api.DELETE("/users/:id/favorites/:hotelId", middleware.RequireAuth(), travelHandler.RemoveFavorite)
+		api.GET("/users/:id/companions", middleware.RequireAuth(), companionHandler.ListCompanions)
+		api.POST("/users/:id/companions", middleware.RequireAuth(), companionHandler.CreateCompanion)
+		api.GET("/users/:id/companions/:companionId", middleware.RequireAuth(), companionHandler.GetCompanion)
+		api.PUT("/users/:id/companions/:companionId", middleware.RequireAuth(), companionHandler.ReplaceCompanion)
+		api.DELETE("/users/:id/companions/:companionId", middleware.RequireAuth(), companionHandler.DeleteCompanion)
+		api.GET("/users/:id/documents", middleware.RequireAuth(), travelDocumentHandler.ListDocuments) // own and companions' documents, numbers are masked
+		api.POST("/users/:id/documents", middleware.RequireAuth(), travelDocumentHandler.CreateDocument)
+		api.GET("/users/:id/documents/:documentId", middleware.RequireAuth(), travelDocumentHandler.GetDocument)
+		api.DELETE("/users/:id/documents/:documentId", middleware.RequireAuth(), travelDocumentHandler.DeleteDocument)
+		api.POST("/users/:id/documents/:documentId/reveal", middleware.RequireAuth(), middleware.ForbidImpersonation(), travelDocumentHandler.RevealDocument) // {"reason"}, audited
+		api.PUT("/users/:id/avatar", middleware.RequireAuth(), avatarHandler.UploadAvatar) // multipart field "avatar": JPEG/PNG/WebP
+		api.POST("/users/:id/phone/verification", middleware.RequireAuth(), middleware.ForbidImpersonation(), phoneVerificationHandler.SendCode)
+		api.POST("/users/:id/phone/verification/confirm", middleware.RequireAuth(), middleware.ForbidImpersonation(), phoneVerificationHandler.ConfirmCode)
+		api.GET("/users", userHandler.GetUsersHandler)    // ?expand=locations,profile,identities,stats; ?fields=id,first_name,...; admins: ?include_deleted=true / ?only_deleted=true
+
+		api.PUT("/users/:id/password", middleware.RequireAuth(), middleware.ForbidImpersonation(), authHandler.ChangePassword)
 
 		api.GET("/locations", locationsHandler.GetLocationsHandler)
+
+		api.POST("/impersonation/stop", middleware.RequireAuth(), impersonationHandler.StopImpersonation)
+
+		// GDPR right of access: async archive, then a signed expiring download link
+		api.GET("/me/export", middleware.RequireAuth(), middleware.ForbidImpersonation(), dataExportHandler.GetMyExport)
+		api.GET("/exports/:id/download", dataExportHandler.Download)
+	}
+
+	// --- Admin routes ---
+	admin := api.Group("/admin", middleware.RequireRole("admin"), middleware.ForbidImpersonation())
+	{
+		admin.POST("/users:method", customMethods(map[string]gin.HandlerFunc{
+			"batchUpdate": userHandler.BulkUpdateUsersHandler, // {"ids"|"filter", "changes": {"role"}}, up to 1000 users, one transaction
+			"batchDelete": userHandler.BulkDeleteUsersHandler, // soft delete, same selector
+		}))
+		admin.POST("/users/:id/impersonate", impersonationHandler.StartImpersonation)
+		admin.GET("/users/:id/status", userStatusHandler.GetStatus)
+		admin.PUT("/users/:id/status", userStatusHandler.ChangeStatus) // pending -> active, active <-> suspended, any -> banned
+	}
+
+	// --- Media (local storage backend only) ---
+	if prefix, ok := avatarHandler.MediaPrefix(); ok {
+		r.GET(prefix+"/*path", avatarHandler.ServeMedia)
 	}
 
 	// --- User Hotels ---
-	r.GET("/users/:id/hotels", userHotelsHandler.GetUserHotelsHandler)
+	r.GET("/users/:id/hotels", middleware.RequireAuth(), userHotelsHandler.GetUserHotelsHandler) // ?strategy=&limit=&offset=
 
 	return r
 }
\ No newline at end of file
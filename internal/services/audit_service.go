@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// execer — pool or transaction, lets audit entries be written inside the audited transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// AuditService — writes to the audit log (audit_logs table)
+type AuditService struct {
+	db db_interface
+}
+
+func NewAuditService(db db_interface) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Log — stores one audit entry
+func (s *AuditService) Log(entry models.AuditLog) error {
+	return insertAuditLog(context.Background(), s.db, entry)
+}
+
+func insertAuditLog(ctx context.Context, db execer, entry models.AuditLog) error {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	var ipAddress *string
+	if entry.IPAddress != "" {
+		ipAddress = &entry.IPAddress
+	}
+
+	query := `INSERT INTO audit_logs (actor_id, action, target_type, target_id, ip_address, metadata)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err = db.Exec(ctx, query,
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, ipAddress, metadataJSON)
+
+	return err
+}
@@ -3,11 +3,26 @@ package services
 import (
 	"context"
 	"errors"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+var (
+	ErrInvalidClient          = errors.New("invalid_client")
+	ErrImpersonationForbidden = errors.New("only admins can impersonate users")
+	ErrImpersonateAdmin       = errors.New("admins cannot be impersonated")
+)
+
+const (
+	defaultAccessTokenTTL   = time.Hour
+	defaultImpersonationTTL = 15 * time.Minute
 )
 
 type AuthService struct {
@@ -90,3 +105,199 @@ func (s *AuthService) GetAuthCode(code string) (*models.AuthCode, error) {
 func generateRandomCode() string {
 	return uuid.NewString()
 }
+
+// IssueAccessToken — access token for a regular login; password login is not bound to a registered
+// client, so the token carries no client_id / scope
+func (s *AuthService) IssueAccessToken(userID uuid.UUID, role string) (string, *utils.TokenClaims, error) {
+	return utils.GenerateAccessToken(utils.TokenClaims{
+		UserID: userID.String(),
+		Role:   role,
+	}, durationFromEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL))
+}
+
+// IssueImpersonationToken — short-lived token for admin acting as target; "act" holds the real admin
+func (s *AuthService) IssueImpersonationToken(admin, target models.User) (string, *utils.TokenClaims, error) {
+	if admin.Role != "admin" {
+		return "", nil, ErrImpersonationForbidden
+	}
+	if target.Role == "admin" {
+		return "", nil, ErrImpersonateAdmin
+	}
+
+	return utils.GenerateAccessToken(utils.TokenClaims{
+		UserID: target.ID.String(),
+		Role:   target.Role,
+		Act:    &utils.ActorClaim{Sub: admin.ID.String()},
+	}, durationFromEnv("IMPERSONATION_TOKEN_TTL", defaultImpersonationTTL))
+}
+
+// RevokeToken — puts token jti on the deny list until it expires
+func (s *AuthService) RevokeToken(claims *utils.TokenClaims) error {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (jti) DO NOTHING`
+
+	_, err = s.db.Exec(context.Background(), query, claims.ID, userID, claims.ExpiresAt.Time)
+	return err
+}
+
+// IsTokenRevoked — checks the deny list by jti; the token is revoked as well when its user is gone
+// (deleted, erased or purged), cannot sign in (pending, suspended, banned) or no longer has the token's role.
+// For impersonation tokens the acting admin (act.sub) must still be an active admin.
+func (s *AuthService) IsTokenRevoked(claims *utils.TokenClaims) (bool, error) {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return true, nil
+	}
+	var actorID *uuid.UUID
+	if claims.IsImpersonation() {
+		actor, err := uuid.Parse(claims.Act.Sub)
+		if err != nil {
+			return true, nil
+		}
+		actorID = &actor
+	}
+
+	var revoked bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			  OR NOT EXISTS (SELECT 1 FROM users WHERE id = $2 AND role = $3 AND ` + userActiveCondition + `)
+			  OR ($4::uuid IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE id = $4 AND role = 'admin' AND ` + userActiveCondition + `))`
+
+	err = s.db.QueryRow(context.Background(), query, claims.ID, userID, claims.Role, actorID).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// AccountStatus — effective account status checked at login, only active accounts get tokens
+func (s *AuthService) AccountStatus(userID uuid.UUID) (models.UserStatus, error) {
+	status, err := loadUserStatus(context.Background(), s.db, userID, false)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	return status.Effective(time.Now()), nil
+}
+
+// durationFromEnv — parses a time.Duration env variable, falls back to def
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logrus.Warnf("Invalid %s=%q, using default %s", key, value, def)
+		return def
+	}
+
+	return d
+}
+
+// CreateClient — registers a confidential client and returns its plain secret (shown once)
+func (s *AuthService) CreateClient(clientID, name string) (string, error) {
+	secret := strings.ReplaceAll(uuid.NewString()+uuid.NewString(), "-", "")
+
+	secretHash, err := utils.NewBcryptHasher().HashPassword(secret)
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO oauth_clients (client_id, client_secret_hash, name)
+			  VALUES ($1, $2, $3)`
+
+	if _, err := s.db.Exec(context.Background(), query, clientID, secretHash, name); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// AuthenticateClient — checks client credentials of a gateway / service
+func (s *AuthService) AuthenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	var client models.OAuthClient
+
+	query := `SELECT client_id, client_secret_hash, name, revoked_at
+			  FROM oauth_clients WHERE client_id = $1`
+
+	err := s.db.QueryRow(context.Background(), query, clientID).Scan(
+		&client.ClientID, &client.ClientSecretHash, &client.Name, &client.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	if client.RevokedAt != nil || !utils.CheckPassword(clientSecret, client.ClientSecretHash) {
+		return nil, ErrInvalidClient
+	}
+
+	return &client, nil
+}
+
+// IntrospectToken — RFC 7662: any invalid, expired, revoked token or deleted user gives {"active": false}
+func (s *AuthService) IntrospectToken(token string) (models.TokenIntrospection, error) {
+	inactive := models.TokenIntrospection{Active: false}
+
+	claims, err := utils.ParseAccessToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	revoked, err := s.IsTokenRevoked(claims)
+	if err != nil {
+		return inactive, err
+	}
+	if revoked {
+		return inactive, nil
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return inactive, nil
+	}
+
+	// role is taken from DB, soft-deleted users make the token inactive
+	var role string
+	query := `SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err = s.db.QueryRow(context.Background(), query, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return inactive, nil
+		}
+		return inactive, err
+	}
+
+	result := models.TokenIntrospection{
+		Active:    true,
+		Sub:       claims.UserID,
+		Role:      role,
+		Jti:       claims.ID,
+		TokenType: "Bearer",
+	}
+	if claims.ExpiresAt != nil {
+		result.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		result.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.IsImpersonation() {
+		result.Act = map[string]string{"sub": claims.Act.Sub}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func TestIntrospectToken_Active(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	token, claims, err := utils.GenerateAccessToken(utils.TokenClaims{
+		UserID: userID.String(),
+		Role:   "user",
+	}, time.Minute)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM revoked_tokens WHERE jti = \$1\)\s+OR NOT EXISTS \(SELECT 1 FROM users WHERE id = \$2 AND role = \$3 AND deleted_at IS NULL AND NOT`).
+		WithArgs(claims.ID, userID, claims.Role, (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT role FROM users WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow("admin"))
+
+	result, err := NewAuthService(mock).IntrospectToken(token)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, userID.String(), result.Sub)
+	assert.Equal(t, "admin", result.Role) // role из БД, а не из токена
+	assert.Equal(t, claims.ExpiresAt.Unix(), result.Exp)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIntrospectToken_Revoked(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	token, claims, err := utils.GenerateAccessToken(utils.TokenClaims{UserID: userID.String()}, time.Minute)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM revoked_tokens WHERE jti = \$1\)\s+OR NOT EXISTS \(SELECT 1 FROM users WHERE id = \$2 AND role = \$3 AND deleted_at IS NULL AND NOT`).
+		WithArgs(claims.ID, userID, claims.Role, (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	result, err := NewAuthService(mock).IntrospectToken(token)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Active)
+	assert.Empty(t, result.Sub)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIntrospectToken_DeletedUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	token, claims, err := utils.GenerateAccessToken(utils.TokenClaims{UserID: userID.String()}, time.Minute)
+	assert.NoError(t, err)
+
+	// токены удалённых (и окончательно вычищенных) пользователей считаются отозванными
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(claims.ID, userID, claims.Role, (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	result, err := NewAuthService(mock).IntrospectToken(token)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIntrospectToken_Garbage(t *testing.T) {
+	result, err := NewAuthService(nil).IntrospectToken("not-a-jwt")
+
+	assert.NoError(t, err)
+	assert.False(t, result.Active)
+}
+
+func TestIsTokenRevoked_ChecksAccountStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	_, claims, err := utils.GenerateAccessToken(utils.TokenClaims{UserID: userID.String()}, time.Minute)
+	assert.NoError(t, err)
+
+	// заблокированный аккаунт — токен считается отозванным
+	mock.ExpectQuery(`OR NOT EXISTS \(SELECT 1 FROM users WHERE id = \$2 AND role = \$3 AND deleted_at IS NULL AND NOT \(status IN \('pending', 'banned'\) OR \(status = 'suspended' AND \(status_until IS NULL OR status_until > NOW\(\)\)\)\)\)`).
+		WithArgs(claims.ID, userID, claims.Role, (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	revoked, err := NewAuthService(mock).IsTokenRevoked(claims)
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountStatus_ExpiredSuspensionIsActive(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	until := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(`SELECT status, COALESCE\(status_reason, ''\), status_until, status_changed_at\s+FROM users WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"status", "status_reason", "status_until", "status_changed_at"}).
+			AddRow("suspended", "spam", &until, (*time.Time)(nil)))
+
+	status, err := NewAuthService(mock).AccountStatus(userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "active", status.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsTokenRevoked_RoleChanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	_, claims, err := utils.GenerateAccessToken(utils.TokenClaims{UserID: userID.String(), Role: "admin"}, time.Minute)
+	assert.NoError(t, err)
+
+	// роль сменили после выдачи токена — старый токен больше не даёт прав прежней роли
+	mock.ExpectQuery(`OR NOT EXISTS \(SELECT 1 FROM users WHERE id = \$2 AND role = \$3`).
+		WithArgs(claims.ID, userID, "admin", (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	revoked, err := NewAuthService(mock).IsTokenRevoked(claims)
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsTokenRevoked_ChecksImpersonatingAdmin(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, adminID := uuid.New(), uuid.New()
+	_, claims, err := utils.GenerateAccessToken(utils.TokenClaims{
+		UserID: userID.String(),
+		Role:   "user",
+		Act:    &utils.ActorClaim{Sub: adminID.String()},
+	}, time.Minute)
+	assert.NoError(t, err)
+
+	// админа разжаловали или заблокировали — его токены имперсонации перестают работать
+	mock.ExpectQuery(`OR \(\$4::uuid IS NOT NULL AND NOT EXISTS \(SELECT 1 FROM users WHERE id = \$4 AND role = 'admin' AND deleted_at IS NULL AND NOT`).
+		WithArgs(claims.ID, userID, "user", &adminID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	revoked, err := NewAuthService(mock).IsTokenRevoked(claims)
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
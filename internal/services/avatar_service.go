@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+const (
+	defaultAvatarMaxBytes = 5 << 20 // 5 MiB
+	avatarMaxSide         = 1024
+)
+
+// avatarThumbnailSizes — square thumbnails generated for every upload
+var avatarThumbnailSizes = []int{256, 64}
+
+var ErrAvatarTooLarge = errors.New("avatar file is too large")
+
+type avatarVariant struct {
+	name string
+	img  *image.NRGBA
+}
+
+// AvatarService — validates uploaded avatars, re-encodes them without metadata and stores them
+type AvatarService struct {
+	db       db_interface
+	storage  storage.Storage
+	maxBytes int64
+}
+
+// NewAvatarService — AVATAR_MAX_BYTES overrides the 5 MiB upload limit
+func NewAvatarService(db db_interface, store storage.Storage) *AvatarService {
+	maxBytes := int64(defaultAvatarMaxBytes)
+	if value, err := strconv.ParseInt(os.Getenv("AVATAR_MAX_BYTES"), 10, 64); err == nil && value > 0 {
+		maxBytes = value
+	}
+
+	return &AvatarService{db: db, storage: store, maxBytes: maxBytes}
+}
+
+// MaxBytes — upload size limit
+func (s *AvatarService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Storage — backend the avatars are stored in
+func (s *AvatarService) Storage() storage.Storage {
+	return s.storage
+}
+
+// UploadAvatar — stores the image with thumbnails and points the profile avatar_url at it,
+// files of the previous avatar are removed
+func (s *AvatarService) UploadAvatar(userID uuid.UUID, data []byte) (models.Avatar, error) {
+	ctx := context.Background()
+
+	if int64(len(data)) > s.maxBytes {
+		return models.Avatar{}, ErrAvatarTooLarge
+	}
+
+	var previousURL *string
+	err := s.db.QueryRow(ctx, `
+		SELECT p.avatar_url
+		FROM users u
+		LEFT JOIN user_profiles p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL`, userID).Scan(&previousURL)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Avatar{}, errors.New("user not found")
+		}
+		return models.Avatar{}, err
+	}
+
+	decoded, err := utils.DecodeImage(data)
+	if err != nil {
+		return models.Avatar{}, err
+	}
+	// decoding and re-encoding drops EXIF (GPS, camera serials), orientation is applied to the pixels first
+	img := utils.Orient(utils.FitImage(decoded, avatarMaxSide), utils.JPEGOrientation(data))
+
+	// new directory per upload, cached URLs of the old avatar never show the new image
+	dir := path.Join("avatars", userID.String(), uuid.NewString())
+	variants := []avatarVariant{{name: "original", img: img}}
+	for _, size := range avatarThumbnailSizes {
+		variants = append(variants, avatarVariant{name: strconv.Itoa(size), img: utils.SquareThumbnail(img, size)})
+	}
+
+	avatar := models.Avatar{Thumbnails: make(map[string]string, len(avatarThumbnailSizes))}
+	var keys []string
+	for _, variant := range variants {
+		encoded, contentType, ext, err := utils.EncodeImage(variant.img)
+		if err != nil {
+			s.deleteKeys(ctx, keys)
+			return models.Avatar{}, err
+		}
+
+		key := path.Join(dir, variant.name+ext)
+		if err := s.storage.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), contentType); err != nil {
+			s.deleteKeys(ctx, keys)
+			return models.Avatar{}, err
+		}
+		keys = append(keys, key)
+
+		if variant.name == "original" {
+			avatar.URL = s.storage.URL(key)
+		} else {
+			avatar.Thumbnails[variant.name] = s.storage.URL(key)
+		}
+	}
+
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO user_profiles (user_id, avatar_url)
+		SELECT id, $2 FROM users WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_id) DO UPDATE SET avatar_url = EXCLUDED.avatar_url, updated_at = NOW()`,
+		userID, avatar.URL)
+	if err != nil || tag.RowsAffected() == 0 {
+		s.deleteKeys(ctx, keys)
+		if err == nil {
+			err = errors.New("user not found")
+		}
+		return models.Avatar{}, err
+	}
+
+	if previousURL != nil {
+		s.deletePrevious(ctx, userID, *previousURL)
+	}
+
+	return avatar, nil
+}
+
+// deletePrevious — removes the files of a replaced avatar
+func (s *AvatarService) deletePrevious(ctx context.Context, userID uuid.UUID, url string) {
+	deleteAvatarFiles(ctx, s.storage, userID, url)
+}
+
+// deleteKeys — best effort, a leftover file is not worth failing the request
+func (s *AvatarService) deleteKeys(ctx context.Context, keys []string) {
+	deleteStorageKeys(ctx, s.storage, keys)
+}
+
+// deleteAvatarFiles — removes the original and thumbnails of the user's avatar at url. avatar_url is
+// writable by the user, so only keys under avatars/<userID>/ are touched: URLs not owned by the storage
+// or pointing at other users' files are left alone
+func deleteAvatarFiles(ctx context.Context, store storage.Storage, userID uuid.UUID, url string) {
+	key, ok := storage.KeyFromURL(store, url)
+	if !ok {
+		return
+	}
+	key = path.Clean(key)
+	if !strings.HasPrefix(key, path.Join("avatars", userID.String())+"/") {
+		logrus.WithField("user_id", userID).WithField("key", key).Warn("not deleting avatar outside the user's directory")
+		return
+	}
+
+	dir, ext := path.Dir(key), path.Ext(key)
+	keys := []string{path.Join(dir, "original"+ext)}
+	for _, size := range avatarThumbnailSizes {
+		keys = append(keys, path.Join(dir, strconv.Itoa(size)+ext))
+	}
+	deleteStorageKeys(ctx, store, keys)
+}
+
+func deleteStorageKeys(ctx context.Context, store storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("key", key).Warn("failed to delete avatar file")
+		}
+	}
+}
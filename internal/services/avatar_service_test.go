@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUploadAvatar(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/media")
+	service := NewAvatarService(mock, store)
+	userID := uuid.New()
+
+	// файлы предыдущего аватара удаляются после успешной загрузки
+	oldKey := "avatars/" + userID.String() + "/old/original.jpg"
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(oldKey)), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, oldKey), []byte("old"), 0o644))
+	oldURL := store.URL(oldKey)
+
+	mock.ExpectQuery(`SELECT p.avatar_url\s+FROM users u`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}).AddRow(&oldURL))
+	mock.ExpectExec(`INSERT INTO user_profiles \(user_id, avatar_url\)`).
+		WithArgs(userID, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	avatar, err := service.UploadAvatar(userID, testPNG(t, 2000, 1000))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.True(t, strings.HasPrefix(avatar.URL, "/media/avatars/"+userID.String()+"/"))
+	assert.True(t, strings.HasSuffix(avatar.URL, "/original.jpg")) // непрозрачный PNG -> JPEG
+	assert.Len(t, avatar.Thumbnails, 2)
+
+	key, _ := storage.KeyFromURL(store, avatar.URL)
+	data, err := os.ReadFile(filepath.Join(dir, key))
+	assert.NoError(t, err)
+	img, err := utils.DecodeImage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 1024, 512), img.Bounds())
+
+	key, _ = storage.KeyFromURL(store, avatar.Thumbnails["64"])
+	data, err = os.ReadFile(filepath.Join(dir, key))
+	assert.NoError(t, err)
+	img, err = utils.DecodeImage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+
+	_, err = os.Stat(filepath.Join(dir, oldKey))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUploadAvatar_RejectsNonImage(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	dir := t.TempDir()
+	service := NewAvatarService(mock, storage.NewLocalStorage(dir, "/media"))
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT p.avatar_url`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}).AddRow(nil))
+
+	_, err = service.UploadAvatar(userID, []byte("<svg onload=alert(1)></svg>"))
+	assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestUploadAvatar_KeepsOtherUsersFiles(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/media")
+	userID := uuid.New()
+
+	// avatar_url можно задать через профиль — чужой файл по такой ссылке не удаляется
+	victimKey := "avatars/" + uuid.NewString() + "/a1/original.jpg"
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(victimKey)), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, victimKey), []byte("victim"), 0o644))
+
+	for _, previousURL := range []string{
+		store.URL(victimKey),
+		store.URL("avatars/" + userID.String() + "/../../" + victimKey),
+	} {
+		mock.ExpectQuery(`SELECT p.avatar_url\s+FROM users u`).WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}).AddRow(&previousURL))
+		mock.ExpectExec(`INSERT INTO user_profiles \(user_id, avatar_url\)`).
+			WithArgs(userID, pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		_, err = NewAvatarService(mock, store).UploadAvatar(userID, testPNG(t, 100, 100))
+		assert.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(dir, victimKey))
+		assert.NoError(t, err, previousURL)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
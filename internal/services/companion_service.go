@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// MaxCompanions — saved companions per user
+const MaxCompanions = 20
+
+var (
+	// ErrInvalidCompanion - a companion field failed validation, wraps the reason
+	ErrInvalidCompanion  = errors.New("invalid companion")
+	ErrCompanionNotFound = errors.New("companion not found")
+	ErrTooManyCompanions = fmt.Errorf("at most %d companions are allowed", MaxCompanions)
+)
+
+const companionColumns = `id, user_id, first_name, last_name, birth_date, relationship, created_at, updated_at`
+
+// CompanionService — saved companion profiles of users (family, colleagues) reused when booking;
+// their passports and ID cards are travel documents with companion_id set (TravelDocumentService)
+type CompanionService struct {
+	db  db_interface
+	now func() time.Time
+}
+
+// NewCompanionService creates new service
+func NewCompanionService(db db_interface) *CompanionService {
+	return &CompanionService{db: db, now: time.Now}
+}
+
+// ListCompanions - companions of an active user, oldest first
+func (s *CompanionService) ListCompanions(ctx context.Context, userID uuid.UUID) ([]models.Companion, error) {
+	if err := ensureActiveUser(ctx, s.db, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+companionColumns+` FROM user_companions
+		WHERE user_id = $1
+		ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	companions := []models.Companion{}
+	for rows.Next() {
+		companion, err := scanCompanion(rows)
+		if err != nil {
+			return nil, err
+		}
+		companions = append(companions, companion)
+	}
+	return companions, rows.Err()
+}
+
+// GetCompanion - one companion of the user
+func (s *CompanionService) GetCompanion(ctx context.Context, userID, id uuid.UUID) (models.Companion, error) {
+	companion, err := scanCompanion(s.db.QueryRow(ctx,
+		`SELECT `+companionColumns+` FROM user_companions WHERE id = $1 AND user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Companion{}, ErrCompanionNotFound
+	}
+	return companion, err
+}
+
+// CreateCompanion - saves a new companion, at most MaxCompanions per user
+func (s *CompanionService) CreateCompanion(ctx context.Context, userID uuid.UUID, companion models.Companion) (models.Companion, error) {
+	if err := s.normalizeCompanion(&companion); err != nil {
+		return models.Companion{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Companion{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the users row lock serializes concurrent creates, so the limit holds
+	var count int
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM user_companions WHERE user_id = u.id)
+		FROM users u
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+		FOR UPDATE`, userID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Companion{}, errors.New("user not found")
+		}
+		return models.Companion{}, err
+	}
+	if count >= MaxCompanions {
+		return models.Companion{}, ErrTooManyCompanions
+	}
+
+	created, err := scanCompanion(tx.QueryRow(ctx, `
+		INSERT INTO user_companions (id, user_id, first_name, last_name, birth_date, relationship)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+companionColumns,
+		uuid.New(), userID, companion.FirstName, companion.LastName, dateValue(companion.BirthDate), companion.Relationship))
+	if err != nil {
+		return models.Companion{}, err
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+// ReplaceCompanion - replaces all fields of a companion
+func (s *CompanionService) ReplaceCompanion(ctx context.Context, userID, id uuid.UUID, companion models.Companion) (models.Companion, error) {
+	if err := s.normalizeCompanion(&companion); err != nil {
+		return models.Companion{}, err
+	}
+
+	updated, err := scanCompanion(s.db.QueryRow(ctx, `
+		UPDATE user_companions
+		SET first_name = $3, last_name = $4, birth_date = $5, relationship = $6, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+companionColumns,
+		id, userID, companion.FirstName, companion.LastName, dateValue(companion.BirthDate), companion.Relationship))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Companion{}, ErrCompanionNotFound
+	}
+	return updated, err
+}
+
+// DeleteCompanion - removes a companion of the user together with its travel documents
+func (s *CompanionService) DeleteCompanion(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM user_companions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanionNotFound
+	}
+	return nil
+}
+
+// normalizeCompanion — validates in place: trimmed names, known relationship, birth date in the past
+func (s *CompanionService) normalizeCompanion(companion *models.Companion) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidCompanion, fmt.Sprintf(format, args...))
+	}
+
+	companion.FirstName = strings.TrimSpace(companion.FirstName)
+	companion.LastName = strings.TrimSpace(companion.LastName)
+	for _, name := range []struct{ field, value string }{
+		{"first_name", companion.FirstName}, {"last_name", companion.LastName},
+	} {
+		if name.value == "" || len([]rune(name.value)) > 100 {
+			return invalid("%s must be 1 to 100 characters", name.field)
+		}
+	}
+
+	if !slices.Contains(models.CompanionRelationships, companion.Relationship) {
+		return invalid("relationship must be one of %s", strings.Join(models.CompanionRelationships, ", "))
+	}
+
+	if birth := companion.BirthDate; birth != nil && (birth.After(s.now()) || birth.Year() < 1900) {
+		return invalid("birth_date must be a past date after 1900")
+	}
+	return nil
+}
+
+func scanCompanion(row pgx.Row) (models.Companion, error) {
+	var companion models.Companion
+	var birthDate *time.Time
+
+	err := row.Scan(&companion.ID, &companion.UserID, &companion.FirstName, &companion.LastName, &birthDate,
+		&companion.Relationship, &companion.CreatedAt, &companion.UpdatedAt)
+	if err != nil {
+		return models.Companion{}, err
+	}
+
+	companion.BirthDate = dateOf(birthDate)
+	return companion, nil
+}
+
+// dateValue / dateOf — models.Date <-> the DATE column value
+func dateValue(date *models.Date) *time.Time {
+	if date == nil {
+		return nil
+	}
+	return &date.Time
+}
+
+func dateOf(value *time.Time) *models.Date {
+	if value == nil {
+		return nil
+	}
+	return &models.Date{Time: *value}
+}
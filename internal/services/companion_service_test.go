@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+var companionRowColumns = []string{"id", "user_id", "first_name", "last_name", "birth_date", "relationship",
+	"created_at", "updated_at"}
+
+func TestCreateCompanion_Normalizes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, companionID := uuid.New(), uuid.New()
+	birth := time.Date(2015, 3, 14, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \(SELECT COUNT\(\*\) FROM user_companions WHERE user_id = u.id\)\s+FROM users u\s+WHERE u.id = \$1 AND u.deleted_at IS NULL\s+FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`INSERT INTO user_companions \(id, user_id, first_name, last_name, birth_date, relationship\)`).
+		WithArgs(pgxmock.AnyArg(), userID, "Lena", "Muster", &birth, "child").
+		WillReturnRows(pgxmock.NewRows(companionRowColumns).
+			AddRow(companionID, userID, "Lena", "Muster", &birth, "child", now, now))
+	mock.ExpectCommit()
+
+	companion, err := NewCompanionService(mock).CreateCompanion(context.Background(), userID, models.Companion{
+		FirstName:    " Lena ",
+		LastName:     "Muster",
+		BirthDate:    &models.Date{Time: birth},
+		Relationship: "child",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, companionID, companion.ID)
+	assert.Equal(t, birth, companion.BirthDate.Time)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCompanion_Limit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(MaxCompanions))
+	mock.ExpectRollback()
+
+	_, err = NewCompanionService(mock).CreateCompanion(context.Background(), userID,
+		models.Companion{FirstName: "Max", LastName: "Muster", Relationship: "colleague"})
+
+	assert.ErrorIs(t, err, ErrTooManyCompanions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCompanion_Validation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	service := NewCompanionService(mock)
+	service.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	valid := models.Companion{FirstName: "Max", LastName: "Muster", Relationship: "friend"}
+
+	for name, change := range map[string]func(c *models.Companion){
+		"blank name":           func(c *models.Companion) { c.FirstName = "  " },
+		"unknown relationship": func(c *models.Companion) { c.Relationship = "pet" },
+		"born in the future": func(c *models.Companion) {
+			c.BirthDate = &models.Date{Time: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}
+		},
+	} {
+		companion := valid
+		change(&companion)
+		_, err := service.CreateCompanion(context.Background(), uuid.New(), companion)
+		assert.ErrorIs(t, err, ErrInvalidCompanion, name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceCompanion_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, companionID := uuid.New(), uuid.New()
+	mock.ExpectQuery(`UPDATE user_companions\s+SET first_name = \$3`).
+		WithArgs(companionID, userID, "Max", "Muster", (*time.Time)(nil), "friend").
+		WillReturnRows(pgxmock.NewRows(companionRowColumns))
+
+	_, err = NewCompanionService(mock).ReplaceCompanion(context.Background(), userID, companionID,
+		models.Companion{FirstName: "Max", LastName: "Muster", Relationship: "friend"})
+
+	assert.ErrorIs(t, err, ErrCompanionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
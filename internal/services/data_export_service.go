@@ -0,0 +1,324 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+const (
+	defaultExportRetention       = 7 * 24 * time.Hour // how long a ready archive is kept
+	defaultExportDownloadTTL     = 15 * time.Minute   // lifetime of one signed download URL
+	defaultExportBuildTimeout    = 30 * time.Minute   // a build still in progress after this is considered lost
+	defaultExportCleanupInterval = time.Hour
+)
+
+var (
+	ErrExportNotFound        = errors.New("export not found")
+	ErrExportLinkInvalid     = errors.New("download link is invalid or expired")
+	ErrExportNotDownloadable = errors.New("export is not ready or already expired")
+)
+
+// DataExportService — GDPR right of access: builds ZIP archives of user data in background
+type DataExportService struct {
+	db              db_interface
+	dir             string
+	retention       time.Duration
+	downloadTTL     time.Duration
+	buildTimeout    time.Duration
+	cleanupInterval time.Duration
+}
+
+// NewDataExportService — archives go to EXPORT_DIR (temp dir by default), EXPORT_RETENTION,
+// EXPORT_DOWNLOAD_TTL, EXPORT_BUILD_TIMEOUT and EXPORT_CLEANUP_INTERVAL override the defaults
+func NewDataExportService(db db_interface) *DataExportService {
+	dir := os.Getenv("EXPORT_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "selena-users-exports")
+	}
+
+	return &DataExportService{
+		db:              db,
+		dir:             dir,
+		retention:       durationFromEnv("EXPORT_RETENTION", defaultExportRetention),
+		downloadTTL:     durationFromEnv("EXPORT_DOWNLOAD_TTL", defaultExportDownloadTTL),
+		buildTimeout:    durationFromEnv("EXPORT_BUILD_TIMEOUT", defaultExportBuildTimeout),
+		cleanupInterval: durationFromEnv("EXPORT_CLEANUP_INTERVAL", defaultExportCleanupInterval),
+	}
+}
+
+const dataExportColumns = `id, user_id, status, COALESCE(file_path, ''), COALESCE(error, ''), created_at, completed_at, expires_at`
+
+func scanDataExport(row pgx.Row) (models.DataExport, error) {
+	var export models.DataExport
+	err := row.Scan(&export.ID, &export.UserID, &export.Status, &export.FilePath, &export.Error,
+		&export.CreatedAt, &export.CompletedAt, &export.ExpiresAt)
+	return export, err
+}
+
+// RequestExport — returns the running or still downloadable export, otherwise starts a new one
+func (s *DataExportService) RequestExport(userID uuid.UUID) (models.DataExport, error) {
+	export, err := s.LatestExport(userID)
+	if err == nil && s.reusable(export) {
+		return export, nil
+	}
+	if err != nil && !errors.Is(err, ErrExportNotFound) {
+		return models.DataExport{}, err
+	}
+
+	query := `INSERT INTO data_exports (user_id, status) VALUES ($1, $2) RETURNING ` + dataExportColumns
+	export, err = scanDataExport(s.db.QueryRow(context.Background(), query, userID, models.DataExportPending))
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	go s.build(export)
+
+	return export, nil
+}
+
+// reusable — a build in progress is reused until buildTimeout, after that it is lost
+// (e.g. the instance restarted) and a new export is started
+func (s *DataExportService) reusable(export models.DataExport) bool {
+	switch export.Status {
+	case models.DataExportPending, models.DataExportProcessing:
+		return export.CreatedAt.After(time.Now().Add(-s.buildTimeout))
+	case models.DataExportReady:
+		return export.ExpiresAt != nil && export.ExpiresAt.After(time.Now())
+	}
+	return false
+}
+
+// LatestExport — the most recent export of the user
+func (s *DataExportService) LatestExport(userID uuid.UUID) (models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + `
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	export, err := scanDataExport(s.db.QueryRow(context.Background(), query, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DataExport{}, ErrExportNotFound
+	}
+	return export, err
+}
+
+// DownloadURL — signed link to the archive, valid for downloadTTL (never beyond the archive expiry)
+func (s *DataExportService) DownloadURL(export models.DataExport) (string, time.Time) {
+	expires := time.Now().Add(s.downloadTTL)
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expires) {
+		expires = *export.ExpiresAt
+	}
+
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{
+		"expires":   {exp},
+		"signature": {utils.Sign(export.ID.String() + ":" + exp)},
+	}
+
+	return fmt.Sprintf("/api/v1/exports/%s/download?%s", export.ID, query.Encode()), expires
+}
+
+// OpenDownload — checks the signed link and returns the archive path
+func (s *DataExportService) OpenDownload(id uuid.UUID, expires, signature string) (string, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp || !utils.VerifySignature(id.String()+":"+expires, signature) {
+		return "", ErrExportLinkInvalid
+	}
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1`
+	export, err := scanDataExport(s.db.QueryRow(context.Background(), query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrExportNotFound
+		}
+		return "", err
+	}
+	if export.Status != models.DataExportReady || export.ExpiresAt == nil || export.ExpiresAt.Before(time.Now()) {
+		return "", ErrExportNotDownloadable
+	}
+
+	return export.FilePath, nil
+}
+
+// RunCleanup — cleans up at once, recovering builds lost by a restart, then every cleanupInterval
+// until ctx is done
+func (s *DataExportService) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Cleanup(ctx); err != nil {
+			logrus.WithError(err).Error("Data export cleanup failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup — marks builds in progress longer than buildTimeout as failed, removes expired
+// archives with their rows
+func (s *DataExportService) Cleanup(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE data_exports SET status = $1, error = $2, completed_at = NOW()
+		WHERE status IN ($3, $4) AND created_at < $5`,
+		models.DataExportFailed, "build timed out", models.DataExportPending, models.DataExportProcessing,
+		time.Now().Add(-s.buildTimeout))
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		DELETE FROM data_exports WHERE status = $1 AND expires_at < NOW()
+		RETURNING COALESCE(file_path, '')`, models.DataExportReady)
+	if err != nil {
+		return err
+	}
+	paths, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	removeExportFiles(paths)
+	return nil
+}
+
+// removeExportFiles — deletes archives whose rows are gone already, a file that failed to go is only logged
+func removeExportFiles(paths []string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.WithError(err).WithField("path", path).Warn("Failed to remove data export")
+		}
+	}
+}
+
+// build — collects the data and writes the archive, runs in its own goroutine
+func (s *DataExportService) build(export models.DataExport) {
+	ctx := context.Background()
+	log := logrus.WithField("export_id", export.ID)
+
+	_, err := s.db.Exec(ctx, `UPDATE data_exports SET status = $1 WHERE id = $2`, models.DataExportProcessing, export.ID)
+	if err == nil {
+		var path string
+		path, err = s.writeArchive(ctx, export)
+		if err == nil {
+			_, err = s.db.Exec(ctx, `
+				UPDATE data_exports
+				SET status = $1, file_path = $2, completed_at = NOW(), expires_at = $3
+				WHERE id = $4`,
+				models.DataExportReady, path, time.Now().Add(s.retention), export.ID)
+		}
+	}
+	if err == nil {
+		log.Info("Data export is ready")
+		return
+	}
+
+	log.WithError(err).Error("Data export failed")
+	_, updateErr := s.db.Exec(ctx, `
+		UPDATE data_exports SET status = $1, error = $2, completed_at = NOW() WHERE id = $3`,
+		models.DataExportFailed, "failed to build the archive", export.ID)
+	if updateErr != nil {
+		log.WithError(updateErr).Error("Failed to mark data export as failed")
+	}
+}
+
+// writeArchive — ZIP with manifest.json and one JSON file per collected section
+func (s *DataExportService) writeArchive(ctx context.Context, export models.DataExport) (string, error) {
+	sections, err := collectUserData(ctx, s.db, export.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.dir, export.ID.String()+".zip")
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	names := make([]string, 0, len(userDataCollectors))
+	for _, collector := range userDataCollectors {
+		names = append(names, collector.name+".json")
+	}
+	manifest := map[string]any{
+		"export_id":    export.ID,
+		"user_id":      export.UserID,
+		"generated_at": time.Now().UTC(),
+		"files":        names,
+	}
+	if err := writeJSONEntry(archive, "manifest.json", manifest); err != nil {
+		return "", err
+	}
+	for _, collector := range userDataCollectors {
+		if err := writeJSONEntry(archive, collector.name+".json", sections[collector.name]); err != nil {
+			return "", err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", err
+	}
+	return path, file.Sync()
+}
+
+func writeJSONEntry(archive *zip.Writer, name string, data any) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// eraseDataExports — archives hold PII too, rows and files are removed on erasure
+func eraseDataExports(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	rows, err := tx.Query(ctx, `DELETE FROM data_exports WHERE user_id = $1 RETURNING COALESCE(file_path, '')`, userID)
+	if err != nil {
+		return err
+	}
+	paths, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
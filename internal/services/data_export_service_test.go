@@ -0,0 +1,152 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+func TestDataExport_WriteArchive(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+	marketing := true
+
+	mock.ExpectQuery(`FROM users WHERE id = \$1`).WithArgs(userID).
+		WillReturnRows(userRows().AddRow(userID, "John", "Doe", "john@example.com", "user", nil, nil, nil, nil, now, now, nil, 1))
+	mock.ExpectQuery(`FROM user_profiles WHERE user_id = \$1`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"phone", "address", "bio", "avatar_url", "created_at", "updated_at"}))
+	mock.ExpectQuery(`FROM user_preferences WHERE user_id = \$1`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"language", "currency", "timezone", "units", "created_at", "updated_at"}).
+			AddRow("de-AT", nil, "Europe/Vienna", nil, now, now))
+	mock.ExpectQuery(`FROM user_travel_preferences WHERE user_id = \$1`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"preferred_city_ids", "room_type", "budget_min", "budget_max", "currency", "created_at", "updated_at"}))
+	mock.ExpectQuery(`FROM user_favorite_hotels WHERE user_id = \$1\s+ORDER BY created_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"hotel_id", "created_at"}).AddRow("hotel-1", now))
+	mock.ExpectQuery(`FROM user_companions WHERE user_id = \$1\s+ORDER BY created_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "first_name", "last_name", "birth_date", "relationship", "created_at", "updated_at"}).
+			AddRow(uuid.NewString(), "Anna", "Doe", nil, "spouse", now, now))
+	mock.ExpectQuery(`FROM travel_documents WHERE user_id = \$1\s+ORDER BY created_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "companion_id", "type", "number_masked", "country", "expires_on",
+			"created_at", "updated_at"}).AddRow(uuid.NewString(), nil, "passport", "*****0T47", "DE", nil, now, now))
+	mock.ExpectQuery(`FROM oauth_sessions WHERE user_id = \$1\s+ORDER BY created_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "provider", "redirect_uri", "expires_at", "created_at"}).
+			AddRow(uuid.NewString(), "google", "https://app/cb", now, now))
+	mock.ExpectQuery(`SELECT DISTINCT provider, provider_id`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"provider", "provider_id"}).AddRow("google", "g-1"))
+	mock.ExpectQuery(`SELECT consents_updated_at, marketing_emails, notify_email, notify_sms, notify_push\s+FROM user_preferences`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"consents_updated_at", "marketing_emails", "notify_email", "notify_sms", "notify_push"}).
+			AddRow(&now, &marketing, (*bool)(nil), (*bool)(nil), (*bool)(nil)))
+	mock.ExpectQuery(`FROM security_events WHERE user_id = \$1`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "event_type", "ip_address", "user_agent", "metadata", "created_at"}))
+
+	service := &DataExportService{db: mock, dir: t.TempDir()}
+	path, err := service.writeArchive(context.Background(), models.DataExport{ID: uuid.New(), UserID: userID})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	archive, err := zip.OpenReader(path)
+	assert.NoError(t, err)
+	defer archive.Close()
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{
+		"manifest.json", "user.json", "profile.json", "preferences.json", "travel_preferences.json",
+		"favorite_hotels.json", "companions.json", "travel_documents.json", "sessions.json", "identities.json", "consents.json", "security_events.json",
+	}, names)
+
+	// секреты (хеш пароля, токены сессий) в архив не попадают
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, reader)
+		reader.Close()
+		assert.NotContains(t, buf.String(), "password")
+		assert.NotContains(t, buf.String(), "access_token")
+	}
+}
+
+func TestDataExport_SignedDownloadURL(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	expiresAt := time.Now().Add(time.Hour)
+	export := models.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: models.DataExportReady, ExpiresAt: &expiresAt}
+	service := &DataExportService{db: mock, downloadTTL: 15 * time.Minute}
+
+	link, expires := service.DownloadURL(export)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expires, time.Minute)
+
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	query := parsed.Query()
+
+	// подделанная подпись
+	_, err = service.OpenDownload(export.ID, query.Get("expires"), "deadbeef")
+	assert.ErrorIs(t, err, ErrExportLinkInvalid)
+
+	// ссылка на другой экспорт с той же подписью
+	_, err = service.OpenDownload(uuid.New(), query.Get("expires"), query.Get("signature"))
+	assert.ErrorIs(t, err, ErrExportLinkInvalid)
+
+	mock.ExpectQuery(`FROM data_exports WHERE id = \$1`).WithArgs(export.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "status", "file_path", "error", "created_at", "completed_at", "expires_at"}).
+			AddRow(export.ID, export.UserID, models.DataExportReady, "/exports/a.zip", "", time.Now(), nil, &expiresAt))
+
+	path, err := service.OpenDownload(export.ID, query.Get("expires"), query.Get("signature"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/exports/a.zip", path)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExport_StaleBuildIsNotReused(t *testing.T) {
+	service := &DataExportService{buildTimeout: 30 * time.Minute}
+
+	assert.True(t, service.reusable(models.DataExport{Status: models.DataExportProcessing, CreatedAt: time.Now().Add(-time.Minute)}))
+	// сборка потерялась (например, после рестарта) — запускается новый экспорт
+	assert.False(t, service.reusable(models.DataExport{Status: models.DataExportPending, CreatedAt: time.Now().Add(-time.Hour)}))
+}
+
+func TestDataExport_Cleanup(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	dir := t.TempDir()
+	expired := filepath.Join(dir, "expired.zip")
+	assert.NoError(t, os.WriteFile(expired, []byte("zip"), 0o600))
+
+	mock.ExpectExec(`UPDATE data_exports SET status = \$1, error = \$2, completed_at = NOW\(\)\s+WHERE status IN \(\$3, \$4\) AND created_at < \$5`).
+		WithArgs(models.DataExportFailed, "build timed out", models.DataExportPending, models.DataExportProcessing, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(`DELETE FROM data_exports WHERE status = \$1 AND expires_at < NOW\(\)\s+RETURNING`).
+		WithArgs(models.DataExportReady).
+		WillReturnRows(pgxmock.NewRows([]string{"file_path"}).AddRow(expired).AddRow(filepath.Join(dir, "gone.zip")))
+
+	service := &DataExportService{db: mock, dir: dir, buildTimeout: 30 * time.Minute}
+	assert.NoError(t, service.Cleanup(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = os.Stat(expired)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,166 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// dataKeySize — AES-256
+const dataKeySize = 32
+
+var (
+	// ErrNotConfigured - no master key source is configured, encrypted data can be neither written nor read
+	ErrNotConfigured = errors.New("encryption is not configured")
+	// ErrDecrypt - ciphertext, key or associated data do not match
+	ErrDecrypt = errors.New("decryption failed")
+)
+
+// KMS — key management service API: encrypts and decrypts small payloads (data keys) under a named
+// master key. Keyfile implements it locally; a cloud KMS or Vault transit client can be adapted to it.
+type KMS interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// WrappedKey — data key of one record as stored next to it: encrypted by the master key KeyID
+type WrappedKey struct {
+	KeyID   string
+	Wrapped []byte
+}
+
+// Envelope — field-level envelope encryption: every record gets its own random AES-256-GCM data key,
+// fields are encrypted with it and only the wrapped data key is stored. Rotating the master key
+// re-wraps data keys, the encrypted fields stay untouched.
+type Envelope struct {
+	kms   KMS
+	keyID string // master key new data keys are wrapped with
+}
+
+// NewEnvelope creates envelope wrapping new data keys with the master key keyID of kms
+func NewEnvelope(kms KMS, keyID string) *Envelope {
+	return &Envelope{kms: kms, keyID: keyID}
+}
+
+// NewEnvelopeFromEnv — master keys from the keyfile at DOCUMENTS_KEYFILE, ErrNotConfigured when unset
+func NewEnvelopeFromEnv() (*Envelope, error) {
+	path := os.Getenv("DOCUMENTS_KEYFILE")
+	if path == "" {
+		return nil, ErrNotConfigured
+	}
+
+	keyfile, err := LoadKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewEnvelope(keyfile, keyfile.CurrentKeyID()), nil
+}
+
+// CurrentKeyID - master key new data keys are wrapped with
+func (e *Envelope) CurrentKeyID() string {
+	return e.keyID
+}
+
+// NewRecordKey - fresh data key for a new record and its wrapped form to store
+func (e *Envelope) NewRecordKey(ctx context.Context) (*RecordKey, WrappedKey, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, WrappedKey{}, err
+	}
+
+	wrapped, err := e.kms.Encrypt(ctx, e.keyID, dataKey)
+	if err != nil {
+		return nil, WrappedKey{}, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	key, err := newRecordKey(dataKey)
+	if err != nil {
+		return nil, WrappedKey{}, err
+	}
+	return key, WrappedKey{KeyID: e.keyID, Wrapped: wrapped}, nil
+}
+
+// RecordKey - unwraps the stored data key of a record
+func (e *Envelope) RecordKey(ctx context.Context, wrapped WrappedKey) (*RecordKey, error) {
+	dataKey, err := e.kms.Decrypt(ctx, wrapped.KeyID, wrapped.Wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return newRecordKey(dataKey)
+}
+
+// Rewrap - the data key wrapped with the current master key; rewrapped is false when it already is
+func (e *Envelope) Rewrap(ctx context.Context, wrapped WrappedKey) (WrappedKey, bool, error) {
+	if wrapped.KeyID == e.keyID {
+		return wrapped, false, nil
+	}
+
+	dataKey, err := e.kms.Decrypt(ctx, wrapped.KeyID, wrapped.Wrapped)
+	if err != nil {
+		return WrappedKey{}, false, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	rewrapped, err := e.kms.Encrypt(ctx, e.keyID, dataKey)
+	if err != nil {
+		return WrappedKey{}, false, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return WrappedKey{KeyID: e.keyID, Wrapped: rewrapped}, true, nil
+}
+
+// RecordKey — plaintext data key of one record, encrypts and decrypts its fields
+type RecordKey struct {
+	aead cipher.AEAD
+}
+
+func newRecordKey(dataKey []byte) (*RecordKey, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordKey{aead: aead}, nil
+}
+
+// Seal - nonce || ciphertext of a field; aad binds it to its record and column
+// (e.g. "travel_documents/<id>/number") so it cannot be moved to another one
+func (k *RecordKey) Seal(plaintext, aad []byte) ([]byte, error) {
+	return seal(k.aead, plaintext, aad)
+}
+
+// Open - plaintext of a sealed field, ErrDecrypt when it was tampered with or aad differs
+func (k *RecordKey) Open(sealed, aad []byte) ([]byte, error) {
+	return open(k.aead, sealed, aad)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,110 @@
+package encryption
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKeyfile — keyfile with a fresh key per moment (the last one is current) and the key ids
+func writeKeyfile(t *testing.T, moments ...time.Time) (string, []string) {
+	lines, ids := []string{"# test keys"}, []string{}
+	for _, moment := range moments {
+		line, err := NewKeyfileLine(moment)
+		assert.NoError(t, err)
+		lines = append(lines, line)
+		ids = append(ids, strings.Fields(line)[0])
+	}
+
+	path := filepath.Join(t.TempDir(), "documents.keys")
+	assert.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	return path, ids
+}
+
+func TestEnvelope_SealOpen(t *testing.T) {
+	path, _ := writeKeyfile(t, time.Now())
+	keyfile, err := LoadKeyfile(path)
+	assert.NoError(t, err)
+	envelope := NewEnvelope(keyfile, keyfile.CurrentKeyID())
+	ctx := context.Background()
+
+	key, wrapped, err := envelope.NewRecordKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, keyfile.CurrentKeyID(), wrapped.KeyID)
+
+	sealed, err := key.Seal([]byte("C01X00T47"), []byte("travel_documents/1/number"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sealed), "C01X00T47")
+
+	// ключ записи восстанавливается из сохранённой обёртки
+	unwrapped, err := envelope.RecordKey(ctx, wrapped)
+	assert.NoError(t, err)
+	plaintext, err := unwrapped.Open(sealed, []byte("travel_documents/1/number"))
+	assert.NoError(t, err)
+	assert.Equal(t, "C01X00T47", string(plaintext))
+
+	// шифротекст нельзя перенести в другую запись
+	_, err = unwrapped.Open(sealed, []byte("travel_documents/2/number"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = unwrapped.Open(sealed, []byte("travel_documents/1/number"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestEnvelope_Rewrap(t *testing.T) {
+	now := time.Now()
+	path, ids := writeKeyfile(t, now.Add(-time.Hour), now)
+	keyfile, err := LoadKeyfile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ids[1], keyfile.CurrentKeyID())
+	ctx := context.Background()
+
+	old := NewEnvelope(keyfile, ids[0])
+	key, wrapped, err := old.NewRecordKey(ctx)
+	assert.NoError(t, err)
+	sealed, err := key.Seal([]byte("X1234567"), nil)
+	assert.NoError(t, err)
+
+	current := NewEnvelope(keyfile, keyfile.CurrentKeyID())
+	rewrapped, changed, err := current.Rewrap(ctx, wrapped)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, keyfile.CurrentKeyID(), rewrapped.KeyID)
+
+	// поле остаётся прежним, меняется только обёртка ключа
+	key, err = current.RecordKey(ctx, rewrapped)
+	assert.NoError(t, err)
+	plaintext, err := key.Open(sealed, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "X1234567", string(plaintext))
+
+	_, changed, err = current.Rewrap(ctx, rewrapped)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestLoadKeyfile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty":        "# nothing here\n",
+		"no key":       "k1\n",
+		"not base64":   "k1 !!!\n",
+		"short key":    "k1 c2hvcnQ=\n",
+		"duplicate id": "k1 " + strings.Repeat("A", 43) + "=\nk1 " + strings.Repeat("B", 43) + "=\n",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_"))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := LoadKeyfile(path)
+		assert.Error(t, err, name)
+	}
+
+	t.Setenv("DOCUMENTS_KEYFILE", "")
+	_, err := NewEnvelopeFromEnv()
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
@@ -0,0 +1,98 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Keyfile — master keys from a local file, one "<key-id> <base64 32-byte key>" per line, # starts
+// a comment. The last key is the current one; older keys stay to unwrap existing data keys until
+// they are rotated (cmd/documents rotate) and may be removed afterwards.
+type Keyfile struct {
+	keys    map[string]cipher.AEAD
+	current string
+}
+
+// LoadKeyfile reads and validates the keyfile
+func LoadKeyfile(path string) (*Keyfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyfile: %w", err)
+	}
+	defer file.Close()
+
+	keyfile := &Keyfile{keys: map[string]cipher.AEAD{}}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("keyfile line %d: expected \"<key-id> <base64 key>\"", line)
+		}
+		keyID := fields[0]
+		if _, exists := keyfile.keys[keyID]; exists {
+			return nil, fmt.Errorf("keyfile line %d: duplicate key id %s", line, keyID)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("keyfile line %d: %w", line, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("keyfile line %d: %w", line, err)
+		}
+
+		keyfile.keys[keyID] = aead
+		keyfile.current = keyID
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if keyfile.current == "" {
+		return nil, fmt.Errorf("keyfile %s contains no keys", path)
+	}
+
+	return keyfile, nil
+}
+
+// CurrentKeyID - the last key of the file
+func (k *Keyfile) CurrentKeyID() string {
+	return k.current
+}
+
+// Encrypt - AES-256-GCM under the master key, the key id is bound as associated data
+func (k *Keyfile) Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown master key %s", keyID)
+	}
+	return seal(aead, plaintext, []byte(keyID))
+}
+
+func (k *Keyfile) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown master key %s, was it removed before rotation finished?", keyID)
+	}
+	return open(aead, ciphertext, []byte(keyID))
+}
+
+// NewKeyfileLine — a fresh random master key as a keyfile line, its id is the creation time
+func NewKeyfileLine(now time.Time) (string, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("k%s %s", now.UTC().Format("20060102T150405"), base64.StdEncoding.EncodeToString(key)), nil
+}
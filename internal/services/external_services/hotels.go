@@ -2,12 +2,17 @@ package external_services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 )
 
+// ErrHotelNotFound — hotels-service does not know the hotel
+var ErrHotelNotFound = errors.New("hotel not found")
+
 // ===== Models from hotels-service =====
 
 // Hotel — структура данных от HotelService
@@ -76,15 +81,26 @@ func (c *HotelServiceClient) GetHotels() ([]Hotel, error) {
 	return hotels, nil
 }
 
-// ApplyBusinessLogic — фильтр отелей для пользователя
-func (c *HotelServiceClient) ApplyBusinessLogic(userId string, hotels []Hotel) []Hotel {
-	var filtered []Hotel
-	for _, h := range hotels {
-		if h.City == "Augsburg" { // пример фильтра
-			filtered = append(filtered, h)
-		}
+// GetHotel — a single hotel by ID, ErrHotelNotFound when hotels-service answers 404
+func (c *HotelServiceClient) GetHotel(id string) (Hotel, error) {
+	resp, err := c.Client.Get(c.BaseURL + "/hotels/" + url.PathEscape(id))
+	if err != nil {
+		return Hotel{}, fmt.Errorf("failed to get hotel: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Hotel{}, ErrHotelNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Hotel{}, fmt.Errorf("hotel service returned status: %d", resp.StatusCode)
+	}
+
+	var hotel Hotel
+	if err := json.NewDecoder(resp.Body).Decode(&hotel); err != nil {
+		return Hotel{}, fmt.Errorf("failed to decode hotel response: %w", err)
 	}
-	return filtered
+	return hotel, nil
 }
 
 // GetLocations - get locations list
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/recommendations"
+)
+
+const (
+	DefaultRecommendationLimit = 20
+	MaxRecommendationLimit     = 100
+)
+
+// ErrUnknownStrategy - ?strategy= names no registered scoring strategy
+var ErrUnknownStrategy = errors.New("unknown recommendation strategy")
+
+// HotelRecommendationParams - strategy ("" = the default one) and the page
+type HotelRecommendationParams struct {
+	Strategy string
+	Limit    int
+	Offset   int
+}
+
+// HotelRecommendationPage - one page of ranked hotels
+type HotelRecommendationPage struct {
+	Strategy string
+	Hotels   []recommendations.Recommendation
+	Total    int // hotels ranked, on all pages
+}
+
+// HotelRecommendationService — ranks hotels of hotels-service for a user with a pluggable strategy
+type HotelRecommendationService struct {
+	db              db_interface
+	hotels          HotelCatalog
+	strategies      map[string]recommendations.Scorer
+	defaultStrategy string
+}
+
+// NewHotelRecommendationService creates new service, defaultStrategy must be one of strategies
+func NewHotelRecommendationService(db db_interface, hotels HotelCatalog, strategies map[string]recommendations.Scorer, defaultStrategy string) *HotelRecommendationService {
+	if _, ok := strategies[defaultStrategy]; !ok {
+		logrus.Warnf("unknown recommendation strategy %q, using %s", defaultStrategy, recommendations.StrategyPersonal)
+		defaultStrategy = recommendations.StrategyPersonal
+	}
+	return &HotelRecommendationService{db: db, hotels: hotels, strategies: strategies, defaultStrategy: defaultStrategy}
+}
+
+// Recommend - hotels ranked by the user's home city / country, preferred cities and favorites
+func (s *HotelRecommendationService) Recommend(ctx context.Context, userID uuid.UUID, params HotelRecommendationParams) (HotelRecommendationPage, error) {
+	name := params.Strategy
+	if name == "" {
+		name = s.defaultStrategy
+	}
+	scorer, ok := s.strategies[name]
+	if !ok {
+		return HotelRecommendationPage{}, fmt.Errorf("%w %q, expected one of %s", ErrUnknownStrategy, name, strings.Join(s.Strategies(), ", "))
+	}
+
+	profile, err := s.profile(ctx, userID)
+	if err != nil {
+		return HotelRecommendationPage{}, err
+	}
+
+	hotels, err := s.hotels.GetHotels()
+	if err != nil {
+		return HotelRecommendationPage{}, fmt.Errorf("%w: %w", ErrHotelServiceUnavailable, err)
+	}
+
+	ranked := recommendations.Rank(hotels, profile, scorer)
+	start := min(params.Offset, len(ranked))
+	end := min(start+params.Limit, len(ranked))
+
+	return HotelRecommendationPage{Strategy: name, Hotels: ranked[start:end], Total: len(ranked)}, nil
+}
+
+// Strategies - names of the available strategies, sorted
+func (s *HotelRecommendationService) Strategies() []string {
+	names := make([]string, 0, len(s.strategies))
+	for name := range s.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profile — the user's locations, preferred cities and favorites in one query;
+// location IDs are resolved to names through hotels-service, without it only favorites match
+func (s *HotelRecommendationService) profile(ctx context.Context, userID uuid.UUID) (recommendations.Profile, error) {
+	var countryID, cityID *uuid.UUID
+	var preferredCityIDs []uuid.UUID
+	var favoriteIDs []string
+	err := s.db.QueryRow(ctx, `
+		SELECT u.country_id, u.city_id, p.preferred_city_ids,
+			ARRAY(SELECT hotel_id FROM user_favorite_hotels WHERE user_id = u.id)
+		FROM users u
+		LEFT JOIN user_travel_preferences p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL`, userID).Scan(&countryID, &cityID, &preferredCityIDs, &favoriteIDs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return recommendations.Profile{}, errors.New("user not found")
+		}
+		return recommendations.Profile{}, err
+	}
+
+	profile := recommendations.Profile{FavoriteHotelIDs: map[string]bool{}}
+	for _, id := range favoriteIDs {
+		profile.FavoriteHotelIDs[id] = true
+	}
+	if countryID == nil && cityID == nil && len(preferredCityIDs) == 0 {
+		return profile, nil
+	}
+
+	countries, err := s.hotels.GetLocations()
+	if err != nil {
+		logrus.WithError(err).Warn("failed to load locations for hotel recommendations")
+		return profile, nil
+	}
+	for _, country := range countries {
+		if countryID != nil && country.ID == countryID.String() {
+			profile.HomeCountry, profile.HomeCountryCode = country.Name, country.Code
+		}
+		for _, city := range country.Cities {
+			if cityID != nil && city.ID == cityID.String() {
+				profile.HomeCity = city.Name
+			}
+			if slices.ContainsFunc(preferredCityIDs, func(id uuid.UUID) bool { return id.String() == city.ID }) {
+				profile.PreferredCities = append(profile.PreferredCities, city.Name)
+			}
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+	"github.com/vitalii-q/selena-users-service/internal/services/recommendations"
+)
+
+func TestRecommendHotels(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, germany, augsburg, vienna := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	catalog := &fakeHotelCatalog{
+		hotels: map[string]external_services.Hotel{
+			"h-1": {ID: "h-1", Name: "Alpen", City: "Innsbruck", Country: "Austria"},
+			"h-2": {ID: "h-2", Name: "Ring", City: "Vienna", Country: "Austria"},
+			"h-3": {ID: "h-3", Name: "Dom", City: "Augsburg", Country: "Germany"},
+		},
+		locations: []external_services.LocationCountry{
+			{ID: germany.String(), Name: "Germany", Code: "DE", Cities: []external_services.LocationCity{{ID: augsburg.String(), Name: "Augsburg"}}},
+			{ID: uuid.NewString(), Name: "Austria", Code: "AT", Cities: []external_services.LocationCity{{ID: vienna.String(), Name: "Vienna"}}},
+		},
+	}
+
+	mock.ExpectQuery(`SELECT u.country_id, u.city_id, p.preferred_city_ids,\s+ARRAY\(SELECT hotel_id FROM user_favorite_hotels WHERE user_id = u.id\)`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"country_id", "city_id", "preferred_city_ids", "favorites"}).
+			AddRow(&germany, &augsburg, []uuid.UUID{vienna}, []string{"h-1"}))
+
+	service := NewHotelRecommendationService(mock, catalog, recommendations.BuiltinStrategies(), "")
+	page, err := service.Recommend(context.Background(), userID, HotelRecommendationParams{Limit: 2, Offset: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, recommendations.StrategyPersonal, page.Strategy)
+	assert.Equal(t, 3, page.Total)
+	// h-1 (избранное) на первой странице; дальше h-3 и h-2 с равным счётом, по имени
+	if assert.Len(t, page.Hotels, 2) {
+		assert.Equal(t, "h-3", page.Hotels[0].ID)
+		assert.Equal(t, "h-2", page.Hotels[1].ID)
+		assert.Equal(t, recommendations.SignalPreferredCity, page.Hotels[1].Explanation[0].Signal)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecommendHotels_UnknownStrategy(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	service := NewHotelRecommendationService(mock, &fakeHotelCatalog{}, recommendations.BuiltinStrategies(), recommendations.StrategyNearby)
+	_, err = service.Recommend(context.Background(), uuid.New(), HotelRecommendationParams{Strategy: "cheapest", Limit: 10})
+
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
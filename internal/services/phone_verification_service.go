@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/sms"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+const (
+	phoneCodeTTL            = 10 * time.Minute
+	phoneCodeResendInterval = time.Minute
+	phoneCodeMaxAttempts    = 5
+)
+
+var (
+	ErrNoPhone                 = errors.New("profile has no phone number")
+	ErrPhoneAlreadyVerified    = errors.New("phone is already verified")
+	ErrVerificationTooSoon     = errors.New("verification code was sent recently, try again later")
+	ErrNoActiveVerification    = errors.New("no active verification code, request a new one")
+	ErrVerificationCodeInvalid = errors.New("invalid verification code")
+	ErrTooManyAttempts         = errors.New("too many attempts, request a new code")
+)
+
+// PhoneVerificationService — proves phone ownership with a one-time code sent by SMS
+type PhoneVerificationService struct {
+	db     db_interface
+	sender sms.Sender
+}
+
+func NewPhoneVerificationService(db db_interface, sender sms.Sender) *PhoneVerificationService {
+	return &PhoneVerificationService{db: db, sender: sender}
+}
+
+// SendCode — sends a new code to the phone currently stored in the user's profile;
+// sms.ErrNotConfigured without a sender
+func (s *PhoneVerificationService) SendCode(userID uuid.UUID) error {
+	if s.sender == nil {
+		return sms.ErrNotConfigured
+	}
+	ctx := context.Background()
+
+	var phone *string
+	var verifiedAt *time.Time
+	err := s.db.QueryRow(ctx, `
+		SELECT p.phone, p.phone_verified_at
+		FROM user_profiles p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.user_id = $1 AND u.deleted_at IS NULL`, userID).Scan(&phone, &verifiedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if phone == nil {
+		return ErrNoPhone
+	}
+	if verifiedAt != nil {
+		return ErrPhoneAlreadyVerified
+	}
+
+	var lastSentAt time.Time
+	err = s.db.QueryRow(ctx, `
+		SELECT created_at FROM phone_verifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1`, userID).Scan(&lastSentAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err == nil && time.Since(lastSentAt) < phoneCodeResendInterval {
+		return ErrVerificationTooSoon
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO phone_verifications (user_id, phone, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		userID, *phone, phoneCodeHash(userID, *phone, code), time.Now().Add(phoneCodeTTL))
+	if err != nil {
+		return err
+	}
+
+	return s.sender.Send(ctx, *phone, fmt.Sprintf("Your Selena verification code: %s", code))
+}
+
+// ConfirmCode — checks the latest code and marks the profile phone verified
+func (s *PhoneVerificationService) ConfirmCode(userID uuid.UUID, code string) (time.Time, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	var phone, codeHash string
+	var attempts int
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT id, phone, code_hash, attempts, expires_at
+		FROM phone_verifications
+		WHERE user_id = $1 AND consumed_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE`, userID).Scan(&id, &phone, &codeHash, &attempts, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNoActiveVerification
+		}
+		return time.Time{}, err
+	}
+	if time.Now().After(expiresAt) {
+		return time.Time{}, ErrNoActiveVerification
+	}
+	if attempts >= phoneCodeMaxAttempts {
+		return time.Time{}, ErrTooManyAttempts
+	}
+
+	if !utils.VerifySignature(phoneCodeInput(userID, phone, code), codeHash) {
+		if _, err := tx.Exec(ctx, `UPDATE phone_verifications SET attempts = attempts + 1 WHERE id = $1`, id); err != nil {
+			return time.Time{}, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return time.Time{}, err
+		}
+		return time.Time{}, ErrVerificationCodeInvalid
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE phone_verifications SET consumed_at = NOW() WHERE id = $1`, id); err != nil {
+		return time.Time{}, err
+	}
+
+	// the code only proves the number it was sent to
+	var verifiedAt time.Time
+	err = tx.QueryRow(ctx, `
+		UPDATE user_profiles SET phone_verified_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND phone = $2
+		RETURNING phone_verified_at`, userID, phone).Scan(&verifiedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNoActiveVerification
+		}
+		return time.Time{}, err
+	}
+
+	return verifiedAt, tx.Commit(ctx)
+}
+
+func generatePhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func phoneCodeInput(userID uuid.UUID, phone, code string) string {
+	return userID.String() + ":" + phone + ":" + code
+}
+
+// phoneCodeHash — keyed hash, a leaked table does not reveal the 6-digit codes
+func phoneCodeHash(userID uuid.UUID, phone, code string) string {
+	return utils.Sign(phoneCodeInput(userID, phone, code))
+}
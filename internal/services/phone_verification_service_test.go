@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/sms"
+)
+
+type recordingSender struct {
+	to      string
+	message string
+}
+
+func (s *recordingSender) Send(_ context.Context, to, message string) error {
+	s.to, s.message = to, message
+	return nil
+}
+
+func TestPhoneVerification_SendCode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	phone := "+4915112345678"
+
+	mock.ExpectQuery(`SELECT p.phone, p.phone_verified_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"phone", "phone_verified_at"}).AddRow(&phone, nil))
+	mock.ExpectQuery(`SELECT created_at FROM phone_verifications`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}))
+	mock.ExpectExec(`INSERT INTO phone_verifications`).
+		WithArgs(userID, phone, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	sender := &recordingSender{}
+	service := NewPhoneVerificationService(mock, sender)
+
+	assert.NoError(t, service.SendCode(userID))
+	assert.Equal(t, phone, sender.to)
+	assert.Regexp(t, `\d{6}$`, sender.message)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPhoneVerification_SendCodeTooSoon(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	phone := "+4915112345678"
+
+	mock.ExpectQuery(`SELECT p.phone, p.phone_verified_at`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"phone", "phone_verified_at"}).AddRow(&phone, nil))
+	mock.ExpectQuery(`SELECT created_at FROM phone_verifications`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(time.Now().Add(-10 * time.Second)))
+
+	service := NewPhoneVerificationService(mock, &recordingSender{})
+	assert.ErrorIs(t, service.SendCode(userID), ErrVerificationTooSoon)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPhoneVerification_SendCodeWithoutSender(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	// без SMS_SENDER код не создаётся и никуда не пишется
+	service := NewPhoneVerificationService(mock, nil)
+	assert.ErrorIs(t, service.SendCode(uuid.New()), sms.ErrNotConfigured)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPhoneVerification_ConfirmCode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, verificationID := uuid.New(), uuid.New()
+	phone := "+4915112345678"
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM phone_verifications\s+WHERE user_id = \$1 AND consumed_at IS NULL`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "phone", "code_hash", "attempts", "expires_at"}).
+			AddRow(verificationID, phone, phoneCodeHash(userID, phone, "123456"), 0, now.Add(time.Minute)))
+	mock.ExpectExec(`UPDATE phone_verifications SET consumed_at = NOW\(\)`).WithArgs(verificationID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(`UPDATE user_profiles SET phone_verified_at = NOW\(\)`).WithArgs(userID, phone).
+		WillReturnRows(pgxmock.NewRows([]string{"phone_verified_at"}).AddRow(now))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	service := NewPhoneVerificationService(mock, &recordingSender{})
+	verifiedAt, err := service.ConfirmCode(userID, "123456")
+	assert.NoError(t, err)
+	assert.Equal(t, now, verifiedAt)
+}
+
+func TestPhoneVerification_ConfirmWrongCode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, verificationID := uuid.New(), uuid.New()
+	phone := "+4915112345678"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM phone_verifications`).WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "phone", "code_hash", "attempts", "expires_at"}).
+			AddRow(verificationID, phone, phoneCodeHash(userID, phone, "123456"), 2, time.Now().Add(time.Minute)))
+	// неудачная попытка засчитывается даже при ошибке
+	mock.ExpectExec(`SET attempts = attempts \+ 1`).WithArgs(verificationID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	service := NewPhoneVerificationService(mock, &recordingSender{})
+	_, err = service.ConfirmCode(userID, "654321")
+	assert.ErrorIs(t, err, ErrVerificationCodeInvalid)
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+var (
+	// ErrInvalidPreference - unknown preference or a value that failed validation, wraps the reason
+	ErrInvalidPreference = errors.New("invalid preference")
+	// ErrInvalidUnits - units other than metric / imperial
+	ErrInvalidUnits = errors.New("invalid units, expected metric or imperial")
+)
+
+// preferenceColumns — patchable user_preferences columns (equal to the JSON keys) and their kind
+var preferenceColumns = map[string]string{
+	"language":         "string",
+	"currency":         "string",
+	"timezone":         "string",
+	"units":            "string",
+	"marketing_emails": "bool",
+	"notify_email":     "bool",
+	"notify_sms":       "bool",
+	"notify_push":      "bool",
+}
+
+// consentColumns — opt-ins, a change of any of them renews consents_updated_at
+var consentColumns = []string{"marketing_emails", "notify_email", "notify_sms", "notify_push"}
+
+const preferenceSelectColumns = `language, currency, timezone, units,
+	marketing_emails, notify_email, notify_sms, notify_push, updated_at`
+
+// PreferencesService — typed per-user preferences with defaults, readable by other services
+type PreferencesService struct {
+	db db_interface
+}
+
+// NewPreferencesService creates new service
+func NewPreferencesService(db db_interface) *PreferencesService {
+	return &PreferencesService{db: db}
+}
+
+// GetPreferences - effective preferences of an active user, defaults for everything not set
+func (s *PreferencesService) GetPreferences(ctx context.Context, userID uuid.UUID) (models.UserPreferences, error) {
+	query := `
+		SELECT ` + prefixColumns("p.", preferenceSelectColumns) + `
+		FROM users u
+		LEFT JOIN user_preferences p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL`
+
+	preferences, err := scanPreferences(s.db.QueryRow(ctx, query, userID), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserPreferences{}, errors.New("user not found")
+		}
+		return models.UserPreferences{}, err
+	}
+	return preferences, nil
+}
+
+// PatchPreferences - sets the given preferences (column -> value, nil resets to the default),
+// values are validated and normalized: BCP 47 language, ISO 4217 currency, IANA timezone
+func (s *PreferencesService) PatchPreferences(ctx context.Context, userID uuid.UUID, changes map[string]any) (models.UserPreferences, error) {
+	columns := make([]string, 0, len(changes))
+	for column, value := range changes {
+		normalized, err := normalizePreference(column, value)
+		if err != nil {
+			return models.UserPreferences{}, fmt.Errorf("%w: %w", ErrInvalidPreference, err)
+		}
+		changes[column] = normalized
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := []any{userID}
+	values := make([]string, 0, len(columns))
+	assignments := []string{"updated_at = NOW()"}
+	var consentChanged []string
+	for _, column := range columns {
+		args = append(args, changes[column])
+		values = append(values, fmt.Sprintf("$%d", len(args)))
+		assignments = append(assignments, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		if preferenceColumns[column] == "bool" {
+			consentChanged = append(consentChanged,
+				fmt.Sprintf("user_preferences.%s IS DISTINCT FROM EXCLUDED.%s", column, column))
+		}
+	}
+
+	insertColumns := append([]string{"user_id"}, columns...)
+	selectValues := append([]string{"id"}, values...)
+	if len(consentChanged) > 0 {
+		insertColumns = append(insertColumns, "consents_updated_at")
+		selectValues = append(selectValues, "NOW()")
+		assignments = append(assignments, fmt.Sprintf(`consents_updated_at = CASE
+			WHEN %s THEN NOW()
+			ELSE user_preferences.consents_updated_at END`, strings.Join(consentChanged, " OR ")))
+	}
+
+	// SELECT from users: deleted users cannot get preferences, no row means not found
+	query := fmt.Sprintf(`
+		INSERT INTO user_preferences (%s)
+		SELECT %s FROM users WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_id) DO UPDATE SET %s
+		RETURNING `+preferenceSelectColumns,
+		strings.Join(insertColumns, ", "), strings.Join(selectValues, ", "), strings.Join(assignments, ", "))
+
+	preferences, err := scanPreferences(s.db.QueryRow(ctx, query, args...), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserPreferences{}, errors.New("user not found")
+		}
+		return models.UserPreferences{}, err
+	}
+	return preferences, nil
+}
+
+// normalizePreference — validated canonical value of one preference, nil stays nil (default)
+func normalizePreference(column string, value any) (any, error) {
+	kind, ok := preferenceColumns[column]
+	if !ok {
+		return nil, fmt.Errorf("unknown preference %s", column)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	if kind == "bool" {
+		if _, ok := value.(bool); !ok {
+			return nil, fmt.Errorf("%s must be a boolean", column)
+		}
+		return value, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string", column)
+	}
+	switch column {
+	case "language":
+		return utils.NormalizeLanguageTag(text)
+	case "currency":
+		return utils.NormalizeCurrency(text)
+	case "timezone":
+		return utils.NormalizeTimezone(text)
+	case "units":
+		if text != models.UnitsMetric && text != models.UnitsImperial {
+			return nil, ErrInvalidUnits
+		}
+	}
+	return text, nil
+}
+
+// scanPreferences — scans preferenceSelectColumns, NULLs are replaced by the defaults
+func scanPreferences(row pgx.Row, userID uuid.UUID) (models.UserPreferences, error) {
+	var language, currency, timezone, units *string
+	var marketing, notifyEmail, notifySMS, notifyPush *bool
+	var updatedAt *time.Time
+
+	if err := row.Scan(&language, &currency, &timezone, &units,
+		&marketing, &notifyEmail, &notifySMS, &notifyPush, &updatedAt); err != nil {
+		return models.UserPreferences{}, err
+	}
+
+	preferences := models.DefaultUserPreferences
+	preferences.UserID = userID
+	preferences.UpdatedAt = updatedAt
+	for target, value := range map[*string]*string{
+		&preferences.Language: language,
+		&preferences.Currency: currency,
+		&preferences.Timezone: timezone,
+		&preferences.Units:    units,
+	} {
+		if value != nil {
+			*target = *value
+		}
+	}
+	for target, value := range map[*bool]*bool{
+		&preferences.MarketingEmails: marketing,
+		&preferences.NotifyEmail:     notifyEmail,
+		&preferences.NotifySMS:       notifySMS,
+		&preferences.NotifyPush:      notifyPush,
+	} {
+		if value != nil {
+			*target = *value
+		}
+	}
+
+	return preferences, nil
+}
+
+// prefixColumns — "a, b" -> "p.a, p.b"
+func prefixColumns(prefix, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, part := range parts {
+		parts[i] = prefix + strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+var preferenceRowColumns = []string{"language", "currency", "timezone", "units",
+	"marketing_emails", "notify_email", "notify_sms", "notify_push", "updated_at"}
+
+func TestGetPreferences_DefaultsForUnset(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	language, notifyPush := "de-AT", false
+	updatedAt := time.Now()
+
+	mock.ExpectQuery(`FROM users u\s+LEFT JOIN user_preferences p ON p.user_id = u.id\s+WHERE u.id = \$1 AND u.deleted_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(preferenceRowColumns).
+			AddRow(&language, (*string)(nil), (*string)(nil), (*string)(nil),
+				(*bool)(nil), (*bool)(nil), (*bool)(nil), &notifyPush, &updatedAt))
+
+	preferences, err := NewPreferencesService(mock).GetPreferences(context.Background(), userID)
+
+	assert.NoError(t, err)
+	expected := models.DefaultUserPreferences
+	expected.UserID = userID
+	expected.Language = "de-AT"
+	expected.NotifyPush = false
+	expected.UpdatedAt = &updatedAt
+	assert.Equal(t, expected, preferences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchPreferences_NormalizesAndTracksConsents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	currency, timezone, marketing := "CHF", "Europe/Zurich", true
+
+	// колонки по алфавиту; изменение opt-in обновляет consents_updated_at
+	mock.ExpectQuery(`INSERT INTO user_preferences \(user_id, currency, marketing_emails, timezone, consents_updated_at\)\s+`+
+		`SELECT id, \$2, \$3, \$4, NOW\(\) FROM users WHERE id = \$1 AND deleted_at IS NULL\s+`+
+		`ON CONFLICT \(user_id\) DO UPDATE SET updated_at = NOW\(\), currency = EXCLUDED.currency, marketing_emails = EXCLUDED.marketing_emails, timezone = EXCLUDED.timezone, `+
+		`consents_updated_at = CASE\s+WHEN user_preferences.marketing_emails IS DISTINCT FROM EXCLUDED.marketing_emails THEN NOW\(\)`).
+		WithArgs(userID, "CHF", true, "Europe/Zurich").
+		WillReturnRows(pgxmock.NewRows(preferenceRowColumns).
+			AddRow((*string)(nil), &currency, &timezone, (*string)(nil),
+				&marketing, (*bool)(nil), (*bool)(nil), (*bool)(nil), (*time.Time)(nil)))
+
+	preferences, err := NewPreferencesService(mock).PatchPreferences(context.Background(), userID, map[string]any{
+		"currency":         "chf",
+		"timezone":         "Europe/Zurich",
+		"marketing_emails": true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CHF", preferences.Currency)
+	assert.Equal(t, "en", preferences.Language)
+	assert.True(t, preferences.MarketingEmails)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchPreferences_Validation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	service := NewPreferencesService(mock)
+	for name, changes := range map[string]map[string]any{
+		"unknown field":    {"theme": "dark"},
+		"bad language":     {"language": "klingon!"},
+		"bad currency":     {"currency": "BTC"},
+		"bad timezone":     {"timezone": "Mars/Olympus"},
+		"bad units":        {"units": "furlongs"},
+		"bool as string":   {"notify_sms": "yes"},
+		"string as number": {"currency": 978.0},
+	} {
+		_, err := service.PatchPreferences(context.Background(), uuid.New(), changes)
+		assert.ErrorIs(t, err, ErrInvalidPreference, name)
+	}
+
+	_, err = service.PatchPreferences(context.Background(), uuid.New(), map[string]any{"timezone": "CET"})
+	assert.ErrorIs(t, err, utils.ErrInvalidTimezone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCollectConsents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	consentsAt := time.Now()
+	marketing := true
+
+	mock.ExpectQuery(`SELECT consents_updated_at, marketing_emails, notify_email, notify_sms, notify_push\s+FROM user_preferences WHERE user_id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"consents_updated_at", "marketing_emails", "notify_email", "notify_sms", "notify_push"}).
+			AddRow(&consentsAt, &marketing, (*bool)(nil), (*bool)(nil), (*bool)(nil)))
+
+	consents, err := collectConsents(context.Background(), mock, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UserConsent{
+		{Purpose: "marketing_emails", Granted: true, Explicit: true, UpdatedAt: &consentsAt},
+		{Purpose: "notify_email", Granted: true},
+		{Purpose: "notify_sms", Granted: false},
+		{Purpose: "notify_push", Granted: true},
+	}, consents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
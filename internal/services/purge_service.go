@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/metrics"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+)
+
+// PurgeMode — what happens to a soft-deleted user after the retention period
+type PurgeMode string
+
+const (
+	PurgeModeDelete    PurgeMode = "delete"    // hard delete, profiles and sessions go with ON DELETE CASCADE
+	PurgeModeAnonymize PurgeMode = "anonymize" // wipe PII as EraseUser does, keep the row so foreign references stay valid
+
+	DefaultPurgeRetention = 30 * 24 * time.Hour
+	DefaultPurgeBatchSize = 500
+)
+
+var ErrInvalidPurgeMode = errors.New("invalid purge mode")
+
+// PurgeOptions — settings of one purge run
+type PurgeOptions struct {
+	Mode      PurgeMode
+	Retention time.Duration // users deleted earlier than now - Retention are purged
+	BatchSize int
+	DryRun    bool // only count candidates, nothing is changed
+}
+
+// PurgeResult — what a purge run did, also stored in purge_runs
+type PurgeResult struct {
+	RunID      uuid.UUID
+	Cutoff     time.Time
+	Candidates int
+	Purged     int
+	Batches    int
+}
+
+// PurgeService — removes soft-deleted users after the retention period
+type PurgeService struct {
+	db    db_interface
+	media storage.Storage // avatars of purged users are deleted from it
+}
+
+func NewPurgeService(db db_interface, media storage.Storage) *PurgeService {
+	return &PurgeService{db: db, media: media}
+}
+
+// anonymizeUsersSQL wipes PII of the users in $1 and leaves a tombstone row with the same ID
+const anonymizeUsersSQL = `
+	UPDATE users
+	SET email = 'deleted-' || id || '@invalid',
+		password_hash = '',
+		first_name = NULL,
+		last_name = NULL,
+		birth = NULL,
+		gender = NULL,
+		country_id = NULL,
+		city_id = NULL,
+		deleted_at = COALESCE(deleted_at, NOW()),
+		purged_at = NOW(),
+		updated_at = NOW()
+	WHERE id = ANY($1)`
+
+// Purge — processes expired soft-deleted users batch by batch, one transaction per batch
+func (s *PurgeService) Purge(ctx context.Context, opts PurgeOptions) (PurgeResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = PurgeModeDelete
+	}
+	if opts.Mode != PurgeModeDelete && opts.Mode != PurgeModeAnonymize {
+		return PurgeResult{}, ErrInvalidPurgeMode
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultPurgeRetention
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultPurgeBatchSize
+	}
+
+	startedAt := time.Now().UTC()
+	result := PurgeResult{Cutoff: startedAt.Add(-opts.Retention)}
+
+	err := s.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM users WHERE deleted_at < $1 AND purged_at IS NULL`, result.Cutoff).
+		Scan(&result.Candidates)
+
+	if err == nil && !opts.DryRun {
+		for {
+			purged, batchErr := s.purgeBatch(ctx, opts, result.Cutoff)
+			if batchErr != nil {
+				err = batchErr
+				break
+			}
+			if purged == 0 {
+				break
+			}
+
+			result.Batches++
+			result.Purged += purged
+			metrics.UsersPurgedTotal.WithLabelValues(string(opts.Mode)).Add(float64(purged))
+
+			if purged < opts.BatchSize {
+				break
+			}
+		}
+	}
+
+	runID, recordErr := s.recordRun(ctx, opts, result, startedAt, err)
+	result.RunID = runID
+	if err != nil {
+		return result, err
+	}
+	if recordErr != nil {
+		return result, recordErr
+	}
+
+	if !opts.DryRun {
+		metrics.PurgeLastSuccessTimestamp.SetToCurrentTime()
+	}
+
+	return result, nil
+}
+
+// purgeBatch — locks up to BatchSize expired users and deletes or anonymizes them
+func (s *PurgeService) purgeBatch(ctx context.Context, opts PurgeOptions, cutoff time.Time) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM users
+		WHERE deleted_at < $1 AND purged_at IS NULL
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`, cutoff, opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var media []userMediaFile
+	var exportFiles []string
+	switch opts.Mode {
+	case PurgeModeDelete:
+		for _, id := range ids {
+			files, err := userMedia(ctx, tx, id)
+			if err != nil {
+				return 0, err
+			}
+			media = append(media, files...)
+		}
+		// data_exports rows go with the cascade, their archives hold PII and are removed after commit
+		rows, err := tx.Query(ctx, `SELECT file_path FROM data_exports WHERE user_id = ANY($1) AND file_path IS NOT NULL`, ids)
+		if err != nil {
+			return 0, err
+		}
+		exportFiles, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return 0, err
+		}
+		// auth_codes has no foreign key to users
+		if _, err := tx.Exec(ctx, `DELETE FROM auth_codes WHERE user_id = ANY($1)`, ids); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = ANY($1)`, ids); err != nil {
+			return 0, err
+		}
+	case PurgeModeAnonymize:
+		// the same erasers as EraseUser, so personal data added later is purged too; the last one
+		// anonymizes the users row into a tombstone
+		for _, id := range ids {
+			_, files, err := eraseUserData(ctx, tx, id)
+			if err != nil {
+				return 0, err
+			}
+			media = append(media, files...)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	deleteUserMedia(ctx, s.media, media)
+	removeExportFiles(exportFiles)
+
+	return len(ids), nil
+}
+
+// recordRun — stores the run in purge_runs, failed runs are recorded with their error
+func (s *PurgeService) recordRun(ctx context.Context, opts PurgeOptions, result PurgeResult, startedAt time.Time, runErr error) (uuid.UUID, error) {
+	var errText *string
+	if runErr != nil {
+		text := runErr.Error()
+		errText = &text
+	}
+
+	var id uuid.UUID
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO purge_runs (mode, dry_run, cutoff, candidates, purged, error, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		string(opts.Mode), opts.DryRun, result.Cutoff, result.Candidates, result.Purged, errText, startedAt).
+		Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record purge run: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurge_DeleteInBatches(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	runID := uuid.New()
+
+	// архив экспорта удаляется вместе с каскадно удалёнными строками data_exports
+	archive := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(archive, []byte("zip"), 0o600))
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users WHERE deleted_at < \$1 AND purged_at IS NULL`).
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+	// полный батч -> берём следующий
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users\s+WHERE deleted_at < \$1 AND purged_at IS NULL\s+ORDER BY deleted_at\s+LIMIT \$2\s+FOR UPDATE SKIP LOCKED`).
+		WithArgs(pgxmock.AnyArg(), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+	// файлы аватаров собираются до каскадного удаления профилей
+	for _, id := range []uuid.UUID{first, second} {
+		mock.ExpectQuery(`SELECT avatar_url FROM user_profiles`).WithArgs(id).
+			WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}))
+	}
+	mock.ExpectQuery(`SELECT file_path FROM data_exports WHERE user_id = ANY\(\$1\) AND file_path IS NOT NULL`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnRows(pgxmock.NewRows([]string{"file_path"}).AddRow(archive))
+	mock.ExpectExec(`DELETE FROM auth_codes WHERE user_id = ANY\(\$1\)`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec(`DELETE FROM users WHERE id = ANY\(\$1\)`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users`).
+		WithArgs(pgxmock.AnyArg(), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(third))
+	mock.ExpectQuery(`SELECT avatar_url FROM user_profiles`).WithArgs(third).
+		WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}))
+	mock.ExpectQuery(`FROM data_exports`).WithArgs([]uuid.UUID{third}).
+		WillReturnRows(pgxmock.NewRows([]string{"file_path"}))
+	mock.ExpectExec(`DELETE FROM auth_codes`).
+		WithArgs([]uuid.UUID{third}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec(`DELETE FROM users`).
+		WithArgs([]uuid.UUID{third}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(`INSERT INTO purge_runs`).
+		WithArgs("delete", false, pgxmock.AnyArg(), 3, 3, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(runID))
+
+	result, err := NewPurgeService(mock, nil).Purge(context.Background(), PurgeOptions{
+		Mode:      PurgeModeDelete,
+		Retention: 30 * 24 * time.Hour,
+		BatchSize: 2,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, runID, result.RunID)
+	assert.Equal(t, 3, result.Purged)
+	assert.Equal(t, 2, result.Batches)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), result.Cutoff, time.Minute)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = os.Stat(archive)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPurge_AnonymizeKeepsTombstone(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users`).WithArgs(pgxmock.AnyArg(), DefaultPurgeBatchSize).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+	// каждый eraser из userDataErasers трогает свою таблицу: новый eraser без покрытия в purge уронит тест
+	for _, id := range []uuid.UUID{first, second} {
+		for _, eraser := range userDataErasers {
+			pattern := `\b` + eraser.name + `\b`
+			switch eraser.name {
+			case "avatar_files":
+				mock.ExpectQuery(`SELECT avatar_url FROM user_profiles`).WithArgs(id).
+					WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}))
+			case "data_exports":
+				mock.ExpectQuery(pattern).WithArgs(id).WillReturnRows(pgxmock.NewRows([]string{"file_path"}))
+			case "users":
+				mock.ExpectExec(`UPDATE users\s+SET email = 'deleted-' \|\| id \|\| '@invalid'`).WithArgs([]uuid.UUID{id}).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			default:
+				mock.ExpectExec(pattern).WithArgs(id).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			}
+		}
+	}
+	mock.ExpectCommit()
+	mock.ExpectQuery(`INSERT INTO purge_runs`).
+		WithArgs("anonymize", false, pgxmock.AnyArg(), 2, 2, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+	result, err := NewPurgeService(mock, nil).Purge(context.Background(), PurgeOptions{Mode: PurgeModeAnonymize})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurge_DryRunChangesNothing(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(42))
+	mock.ExpectQuery(`INSERT INTO purge_runs`).
+		WithArgs("delete", true, pgxmock.AnyArg(), 42, 0, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+	result, err := NewPurgeService(mock, nil).Purge(context.Background(), PurgeOptions{DryRun: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result.Candidates)
+	assert.Equal(t, 0, result.Purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurge_InvalidMode(t *testing.T) {
+	_, err := NewPurgeService(nil, nil).Purge(context.Background(), PurgeOptions{Mode: "truncate"})
+
+	assert.ErrorIs(t, err, ErrInvalidPurgeMode)
+}
@@ -0,0 +1,144 @@
+package recommendations
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+)
+
+// Built-in strategies
+const (
+	StrategyPersonal = "personal" // favorites and preferred cities first
+	StrategyNearby   = "nearby"   // close to home first
+	StrategyDiscover = "discover" // preferred cities, favorites do not count
+)
+
+// Profile — what is known about the traveller; names are compared case-insensitively,
+// empty ones never match. Room type, budget and currency of the travel preferences are not
+// signals yet: the hotels client returns neither room types nor prices to match them against.
+type Profile struct {
+	HomeCity         string // city of users.city_id
+	HomeCountry      string // country of users.country_id
+	HomeCountryCode  string // ISO alpha-2 of the home country
+	PreferredCities  []string
+	FavoriteHotelIDs map[string]bool
+}
+
+// Reason — one matched signal and its contribution to the score
+type Reason struct {
+	Signal  string  `json:"signal"`
+	Message string  `json:"message"`
+	Weight  float64 `json:"weight"`
+}
+
+// Recommendation — a hotel with its score and the reasons it was recommended
+type Recommendation struct {
+	external_services.Hotel
+	Score       float64  `json:"score"`
+	Explanation []Reason `json:"explanation"` // empty when nothing matched
+}
+
+// Scorer — a ranking strategy, the score of a hotel is the sum of the reason weights
+type Scorer interface {
+	Score(hotel external_services.Hotel, profile Profile) []Reason
+}
+
+// Signals a Weighted scorer combines
+const (
+	SignalFavorite      = "favorite"
+	SignalPreferredCity = "preferred_city"
+	SignalHomeCity      = "home_city"
+	SignalHomeCountry   = "home_country"
+)
+
+// signal — matches a hotel against the profile, returns the explanation message
+type signal struct {
+	name  string
+	match func(hotel external_services.Hotel, profile Profile) (string, bool)
+}
+
+// signals — in the order reasons are listed
+var signals = []signal{
+	{name: SignalFavorite, match: func(hotel external_services.Hotel, profile Profile) (string, bool) {
+		return "one of your favorite hotels", profile.FavoriteHotelIDs[hotel.ID]
+	}},
+	{name: SignalPreferredCity, match: func(hotel external_services.Hotel, profile Profile) (string, bool) {
+		return fmt.Sprintf("in %s, one of your preferred cities", hotel.City),
+			slices.ContainsFunc(profile.PreferredCities, func(city string) bool { return sameName(city, hotel.City) })
+	}},
+	{name: SignalHomeCity, match: func(hotel external_services.Hotel, profile Profile) (string, bool) {
+		return fmt.Sprintf("in your home city %s", hotel.City), sameName(profile.HomeCity, hotel.City)
+	}},
+	{name: SignalHomeCountry, match: func(hotel external_services.Hotel, profile Profile) (string, bool) {
+		return fmt.Sprintf("in your home country %s", hotel.Country),
+			sameName(profile.HomeCountry, hotel.Country) || sameName(profile.HomeCountryCode, hotel.Country)
+	}},
+}
+
+// Weighted — scores by matched signals, signal name -> weight; signals without a weight are ignored
+type Weighted map[string]float64
+
+func (w Weighted) Score(hotel external_services.Hotel, profile Profile) []Reason {
+	var reasons []Reason
+	for _, s := range signals {
+		weight := w[s.name]
+		if weight == 0 {
+			continue
+		}
+		if message, ok := s.match(hotel, profile); ok {
+			reasons = append(reasons, Reason{Signal: s.name, Message: message, Weight: weight})
+		}
+	}
+	return reasons
+}
+
+// BuiltinStrategies — strategy name -> scorer, add new strategies here
+func BuiltinStrategies() map[string]Scorer {
+	return map[string]Scorer{
+		StrategyPersonal: Weighted{SignalFavorite: 5, SignalPreferredCity: 3, SignalHomeCity: 2, SignalHomeCountry: 1},
+		StrategyNearby:   Weighted{SignalHomeCity: 5, SignalHomeCountry: 3, SignalPreferredCity: 1, SignalFavorite: 1},
+		StrategyDiscover: Weighted{SignalPreferredCity: 4, SignalHomeCountry: 1},
+	}
+}
+
+// DefaultStrategyFromEnv — RECOMMENDATION_STRATEGY, personal when unset
+func DefaultStrategyFromEnv() string {
+	if name := os.Getenv("RECOMMENDATION_STRATEGY"); name != "" {
+		return name
+	}
+	return StrategyPersonal
+}
+
+// Rank — hotels scored by the scorer, best first; ties keep a stable order by name and ID
+func Rank(hotels []external_services.Hotel, profile Profile, scorer Scorer) []Recommendation {
+	ranked := make([]Recommendation, 0, len(hotels))
+	for _, hotel := range hotels {
+		recommendation := Recommendation{Hotel: hotel, Explanation: []Reason{}}
+		for _, reason := range scorer.Score(hotel, profile) {
+			recommendation.Score += reason.Weight
+			recommendation.Explanation = append(recommendation.Explanation, reason)
+		}
+		ranked = append(ranked, recommendation)
+	}
+
+	slices.SortStableFunc(ranked, func(a, b Recommendation) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		if byName := strings.Compare(a.Name, b.Name); byName != 0 {
+			return byName
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ranked
+}
+
+func sameName(a, b string) bool {
+	return a != "" && strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
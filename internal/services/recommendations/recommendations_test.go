@@ -0,0 +1,65 @@
+package recommendations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+)
+
+var testHotels = []external_services.Hotel{
+	{ID: "h-1", Name: "Alpen", City: "Innsbruck", Country: "Austria"},
+	{ID: "h-2", Name: "Ring", City: "Vienna", Country: "Austria"},
+	{ID: "h-3", Name: "Dom", City: "Augsburg", Country: "Germany"},
+	{ID: "h-4", Name: "Canal", City: "Amsterdam", Country: "Netherlands"},
+}
+
+var testProfile = Profile{
+	HomeCity:         "augsburg",
+	HomeCountry:      "Germany",
+	PreferredCities:  []string{"Vienna"},
+	FavoriteHotelIDs: map[string]bool{"h-1": true},
+}
+
+func rankedIDs(ranked []Recommendation) []string {
+	ids := make([]string, 0, len(ranked))
+	for _, recommendation := range ranked {
+		ids = append(ids, recommendation.ID)
+	}
+	return ids
+}
+
+func TestRank_Strategies(t *testing.T) {
+	strategies := BuiltinStrategies()
+
+	// personal: избранное первым; при равном счёте — по имени (Dom < Ring)
+	assert.Equal(t, []string{"h-1", "h-3", "h-2", "h-4"}, rankedIDs(Rank(testHotels, testProfile, strategies[StrategyPersonal])))
+	assert.Equal(t, []string{"h-3", "h-1", "h-2", "h-4"}, rankedIDs(Rank(testHotels, testProfile, strategies[StrategyNearby])))
+	assert.Equal(t, []string{"h-2", "h-3", "h-1", "h-4"}, rankedIDs(Rank(testHotels, testProfile, strategies[StrategyDiscover])))
+}
+
+func TestRank_Explanation(t *testing.T) {
+	ranked := Rank(testHotels, testProfile, BuiltinStrategies()[StrategyPersonal])
+
+	home := ranked[1]
+	assert.Equal(t, "h-3", home.ID)
+	assert.Equal(t, 3.0, home.Score)
+	assert.Equal(t, []Reason{
+		{Signal: SignalHomeCity, Message: "in your home city Augsburg", Weight: 2},
+		{Signal: SignalHomeCountry, Message: "in your home country Germany", Weight: 1},
+	}, home.Explanation)
+
+	unrelated := ranked[3]
+	assert.Zero(t, unrelated.Score)
+	assert.Equal(t, []Reason{}, unrelated.Explanation)
+}
+
+func TestRank_CustomScorer(t *testing.T) {
+	// стратегия подключается как любой Scorer
+	onlyCountryCode := Weighted{SignalHomeCountry: 1}
+	ranked := Rank(testHotels, Profile{HomeCountryCode: "netherlands"}, onlyCountryCode)
+
+	assert.Equal(t, "h-4", ranked[0].ID)
+	assert.Equal(t, 1.0, ranked[0].Score)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// SecurityEventService — writes to the security event log (security_events table)
+type SecurityEventService struct {
+	db db_interface
+}
+
+func NewSecurityEventService(db db_interface) *SecurityEventService {
+	return &SecurityEventService{db: db}
+}
+
+// Log — stores one security event
+func (s *SecurityEventService) Log(event models.SecurityEvent) error {
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO security_events (user_id, actor_id, event_type, ip_address, user_agent, metadata)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err = s.db.Exec(context.Background(), query,
+		event.UserID, event.ActorID, event.EventType, event.IPAddress, event.UserAgent, metadataJSON)
+
+	return err
+}
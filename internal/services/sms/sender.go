@@ -0,0 +1,87 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sender — delivers a text message to an E.164 phone number
+type Sender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// ErrNotConfigured - SMS_SENDER is not set, messages (and verification codes) cannot be delivered
+var ErrNotConfigured = errors.New("sms sending is not configured")
+
+// NewSenderFromEnv — SMS_SENDER=webhook posts to SMS_WEBHOOK_URL, SMS_SENDER=log logs messages (local dev only),
+// ErrNotConfigured when unset
+func NewSenderFromEnv() (Sender, error) {
+	switch sender := os.Getenv("SMS_SENDER"); sender {
+	case "webhook":
+		url := os.Getenv("SMS_WEBHOOK_URL")
+		if url == "" {
+			return nil, errors.New("SMS_WEBHOOK_URL is required for SMS_SENDER=webhook")
+		}
+		return NewWebhookSender(url), nil
+	case "log":
+		logrus.Warn("SMS_SENDER=log: messages, including verification codes, are written to the log")
+		return LogSender{}, nil
+	case "":
+		return nil, ErrNotConfigured
+	default:
+		return nil, fmt.Errorf("unknown SMS_SENDER %q, expected webhook or log", sender)
+	}
+}
+
+// LogSender — writes messages to the log instead of sending them, codes end up in the log
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, to, message string) error {
+	logrus.WithField("to", to).Infof("SMS (log sender): %s", message)
+	return nil
+}
+
+// WebhookSender — posts {"to", "message"} as JSON to an SMS gateway
+type WebhookSender struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookSender(url string) *WebhookSender {
+	return &WebhookSender{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, to, message string) error {
+	body, err := json.Marshal(map[string]string{"to": to, "message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
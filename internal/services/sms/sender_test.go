@@ -0,0 +1,33 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSenderFromEnv(t *testing.T) {
+	t.Setenv("SMS_SENDER", "")
+	_, err := NewSenderFromEnv()
+	assert.ErrorIs(t, err, ErrNotConfigured)
+
+	// логирование кодов — только по явному выбору
+	t.Setenv("SMS_SENDER", "log")
+	sender, err := NewSenderFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, LogSender{}, sender)
+
+	t.Setenv("SMS_SENDER", "webhook")
+	t.Setenv("SMS_WEBHOOK_URL", "")
+	_, err = NewSenderFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("SMS_WEBHOOK_URL", "http://sms.local/send")
+	sender, err = NewSenderFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "http://sms.local/send", sender.(*WebhookSender).URL)
+
+	t.Setenv("SMS_SENDER", "twilio")
+	_, err = NewSenderFromEnv()
+	assert.Error(t, err)
+}
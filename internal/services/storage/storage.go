@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+)
+
+// Storage — blob store for user media (avatars)
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL — public URL of the object stored under key
+	URL(key string) string
+}
+
+// NewFromEnv — STORAGE_BACKEND=s3 uses the S3_* settings, anything else the local filesystem
+// under MEDIA_DIR (./media by default) served at MEDIA_BASE_URL (/media by default)
+func NewFromEnv() Storage {
+	if os.Getenv("STORAGE_BACKEND") == "s3" {
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    envOr("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	}
+
+	return NewLocalStorage(envOr("MEDIA_DIR", "./media"), envOr("MEDIA_BASE_URL", "/media"))
+}
+
+// KeyFromURL — reverse of URL, false for URLs that do not belong to the storage
+func KeyFromURL(s Storage, url string) (string, bool) {
+	prefix := s.URL("")
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/encryption"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+const (
+	// MaxTravelDocuments — documents per user, companions' included
+	MaxTravelDocuments = 20
+	// DefaultKeyRotationBatchSize — rows re-wrapped per query by RotateKeys
+	DefaultKeyRotationBatchSize = 500
+)
+
+var (
+	// ErrInvalidTravelDocument - a document field failed validation, wraps the reason
+	ErrInvalidTravelDocument  = errors.New("invalid travel document")
+	ErrTravelDocumentNotFound = errors.New("travel document not found")
+	ErrTooManyTravelDocuments = fmt.Errorf("at most %d travel documents are allowed", MaxTravelDocuments)
+	ErrRevealReasonRequired   = errors.New("a reason is required to reveal a document")
+)
+
+// documentNumberPattern — passport / ID card number after removing spaces and upper-casing
+var documentNumberPattern = regexp.MustCompile(`^[A-Z0-9]{4,32}$`)
+
+const travelDocumentColumns = `id, user_id, companion_id, type, country, expires_on, number_masked, created_at, updated_at`
+
+// TravelDocumentService — passports and ID cards with envelope-encrypted numbers
+type TravelDocumentService struct {
+	db       db_interface
+	envelope *encryption.Envelope // nil when no master key is configured
+}
+
+// NewTravelDocumentService creates new service, envelope may be nil: documents are then unavailable
+func NewTravelDocumentService(db db_interface, envelope *encryption.Envelope) *TravelDocumentService {
+	return &TravelDocumentService{db: db, envelope: envelope}
+}
+
+// ListDocuments - documents of an active user with masked numbers, own documents first
+func (s *TravelDocumentService) ListDocuments(ctx context.Context, userID uuid.UUID) ([]models.TravelDocument, error) {
+	if err := ensureActiveUser(ctx, s.db, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+travelDocumentColumns+` FROM travel_documents
+		WHERE user_id = $1
+		ORDER BY companion_id NULLS FIRST, created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.TravelDocument{}
+	for rows.Next() {
+		document, err := scanTravelDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// GetDocument - one document of the user with the masked number
+func (s *TravelDocumentService) GetDocument(ctx context.Context, userID, id uuid.UUID) (models.TravelDocument, error) {
+	document, err := scanTravelDocument(s.db.QueryRow(ctx,
+		`SELECT `+travelDocumentColumns+` FROM travel_documents WHERE id = $1 AND user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.TravelDocument{}, ErrTravelDocumentNotFound
+	}
+	return document, err
+}
+
+// CreateDocument - stores a document with its number encrypted under a new data key;
+// CompanionID must be a companion of the user
+func (s *TravelDocumentService) CreateDocument(ctx context.Context, userID uuid.UUID, document models.TravelDocument) (models.TravelDocument, error) {
+	if s.envelope == nil {
+		return models.TravelDocument{}, encryption.ErrNotConfigured
+	}
+	number, country, err := normalizeDocument(document.Type, document.Number, document.Country)
+	if err != nil {
+		return models.TravelDocument{}, fmt.Errorf("%w: %w", ErrInvalidTravelDocument, err)
+	}
+
+	id := uuid.New()
+	sealed, err := sealDocumentNumber(ctx, s.envelope, "travel_documents", id, number)
+	if err != nil {
+		return models.TravelDocument{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.TravelDocument{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the users row lock serializes concurrent creates, so the limit holds
+	var count int
+	var companionFound bool
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM travel_documents WHERE user_id = u.id),
+			$2::uuid IS NULL OR EXISTS (SELECT 1 FROM user_companions WHERE id = $2 AND user_id = u.id)
+		FROM users u
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+		FOR UPDATE`, userID, document.CompanionID).Scan(&count, &companionFound)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TravelDocument{}, errors.New("user not found")
+		}
+		return models.TravelDocument{}, err
+	}
+	if !companionFound {
+		return models.TravelDocument{}, ErrCompanionNotFound
+	}
+	if count >= MaxTravelDocuments {
+		return models.TravelDocument{}, ErrTooManyTravelDocuments
+	}
+
+	created, err := scanTravelDocument(tx.QueryRow(ctx, `
+		INSERT INTO travel_documents (id, user_id, companion_id, type, country, expires_on,
+			number_encrypted, number_masked, data_key, key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+travelDocumentColumns,
+		id, userID, document.CompanionID, document.Type, country, dateValue(document.ExpiresOn),
+		sealed.encrypted, sealed.masked, sealed.dataKey.Wrapped, sealed.dataKey.KeyID))
+	if err != nil {
+		return models.TravelDocument{}, err
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+// DeleteDocument - removes a document of the user
+func (s *TravelDocumentService) DeleteDocument(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM travel_documents WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTravelDocumentNotFound
+	}
+	return nil
+}
+
+// RevealDocument - the document with its decrypted number; the access is written to the audit log
+// with the reason, nothing is revealed when that fails
+func (s *TravelDocumentService) RevealDocument(ctx context.Context, userID, id uuid.UUID, reason string, audit models.AuditLog) (models.TravelDocument, error) {
+	if strings.TrimSpace(reason) == "" {
+		return models.TravelDocument{}, ErrRevealReasonRequired
+	}
+	if s.envelope == nil {
+		return models.TravelDocument{}, encryption.ErrNotConfigured
+	}
+
+	var encrypted []byte
+	var dataKey encryption.WrappedKey
+	document, err := scanTravelDocument(s.db.QueryRow(ctx, `
+		SELECT `+travelDocumentColumns+`, number_encrypted, data_key, key_id
+		FROM travel_documents WHERE id = $1 AND user_id = $2`, id, userID), &encrypted, &dataKey.Wrapped, &dataKey.KeyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TravelDocument{}, ErrTravelDocumentNotFound
+		}
+		return models.TravelDocument{}, err
+	}
+
+	number, err := openDocumentNumber(ctx, s.envelope, numberSealedAs(document), id, encrypted, dataKey)
+	if err != nil {
+		return models.TravelDocument{}, err
+	}
+
+	audit.Action = models.AuditActionTravelDocumentRevealed
+	audit.TargetType = models.AuditTargetTravelDocument
+	audit.TargetID = &id
+	if audit.Metadata == nil {
+		audit.Metadata = map[string]any{}
+	}
+	audit.Metadata["user_id"] = userID.String()
+	if err := insertAuditLog(ctx, s.db, audit); err != nil {
+		return models.TravelDocument{}, err
+	}
+
+	document.Number = number
+	return document, nil
+}
+
+// sealedNumber — encrypted document number with everything stored next to it
+type sealedNumber struct {
+	encrypted []byte
+	masked    string
+	dataKey   encryption.WrappedKey
+}
+
+// sealDocumentNumber — encrypts a number under a new data key, bound to table/id/number
+func sealDocumentNumber(ctx context.Context, envelope *encryption.Envelope, table string, id uuid.UUID, number string) (sealedNumber, error) {
+	key, wrapped, err := envelope.NewRecordKey(ctx)
+	if err != nil {
+		return sealedNumber{}, err
+	}
+	encrypted, err := key.Seal([]byte(number), documentNumberAAD(table, id))
+	if err != nil {
+		return sealedNumber{}, err
+	}
+	return sealedNumber{encrypted: encrypted, masked: utils.MaskTail(number, 4), dataKey: wrapped}, nil
+}
+
+func openDocumentNumber(ctx context.Context, envelope *encryption.Envelope, table string, id uuid.UUID, encrypted []byte, dataKey encryption.WrappedKey) (string, error) {
+	key, err := envelope.RecordKey(ctx, dataKey)
+	if err != nil {
+		return "", err
+	}
+	number, err := key.Open(encrypted, documentNumberAAD(table, id))
+	if err != nil {
+		return "", err
+	}
+	return string(number), nil
+}
+
+func documentNumberAAD(table string, id uuid.UUID) []byte {
+	return []byte(table + "/" + id.String() + "/number")
+}
+
+// numberSealedAs — the table a stored number is bound to: companion documents moved from
+// user_companions by V26 kept the companion's id and the binding they were sealed with there
+func numberSealedAs(document models.TravelDocument) string {
+	if document.CompanionID != nil && *document.CompanionID == document.ID {
+		return "user_companions"
+	}
+	return "travel_documents"
+}
+
+// normalizeDocument — known type, number upper-cased without spaces (letters and digits only)
+// and the issuing country as ISO alpha-2; returns number and country
+func normalizeDocument(documentType, number, country string) (string, string, error) {
+	if !slices.Contains(models.DocumentTypes, documentType) {
+		return "", "", fmt.Errorf("type must be one of %s", strings.Join(models.DocumentTypes, ", "))
+	}
+	number = strings.ToUpper(strings.Join(strings.Fields(number), ""))
+	if !documentNumberPattern.MatchString(number) {
+		return "", "", errors.New("number must be 4 to 32 letters or digits")
+	}
+	country, err := utils.NormalizeCountryCode(country)
+	if err != nil {
+		return "", "", fmt.Errorf("country: %w", err)
+	}
+	return number, country, nil
+}
+
+// scanTravelDocument — scans travelDocumentColumns followed by extra destinations
+func scanTravelDocument(row pgx.Row, extra ...any) (models.TravelDocument, error) {
+	var document models.TravelDocument
+	var expiresOn *time.Time
+	dest := append([]any{&document.ID, &document.UserID, &document.CompanionID, &document.Type, &document.Country,
+		&expiresOn, &document.Number, &document.CreatedAt, &document.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return models.TravelDocument{}, err
+	}
+	document.ExpiresOn = dateOf(expiresOn)
+	return document, nil
+}
+
+// KeyRotationReport — what RotateKeys changed
+type KeyRotationReport struct {
+	Rewrapped int `json:"rewrapped"` // data keys re-wrapped with the current master key
+}
+
+// RotateKeys - re-wraps every data key not wrapped with the current master key, batchSize rows at a time.
+// Encrypted numbers are not touched; rows changed concurrently are skipped as they get current keys anyway.
+// Safe to re-run: after it finishes old master keys are no longer needed.
+func (s *TravelDocumentService) RotateKeys(ctx context.Context, batchSize int) (KeyRotationReport, error) {
+	var report KeyRotationReport
+	if s.envelope == nil {
+		return report, encryption.ErrNotConfigured
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultKeyRotationBatchSize
+	}
+
+	for {
+		type staleKey struct {
+			id  uuid.UUID
+			key encryption.WrappedKey
+		}
+		rows, err := s.db.Query(ctx, `
+			SELECT id, data_key, key_id FROM travel_documents
+			WHERE key_id <> $2
+			ORDER BY id LIMIT $1`, batchSize, s.envelope.CurrentKeyID())
+		if err != nil {
+			return report, err
+		}
+		batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (staleKey, error) {
+			var k staleKey
+			return k, row.Scan(&k.id, &k.key.Wrapped, &k.key.KeyID)
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, stale := range batch {
+			rewrapped, _, err := s.envelope.Rewrap(ctx, stale.key)
+			if err != nil {
+				return report, fmt.Errorf("travel_documents %s: %w", stale.id, err)
+			}
+			tag, err := s.db.Exec(ctx, `
+				UPDATE travel_documents SET data_key = $3, key_id = $4
+				WHERE id = $1 AND key_id = $2`,
+				stale.id, stale.key.KeyID, rewrapped.Wrapped, rewrapped.KeyID)
+			if err != nil {
+				return report, err
+			}
+			report.Rewrapped += int(tag.RowsAffected())
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/encryption"
+)
+
+var travelDocumentRowColumns = []string{"id", "user_id", "companion_id", "type", "country", "expires_on",
+	"number_masked", "created_at", "updated_at"}
+
+// testKeyfile — temporary keyfile with keys created at the given moments (the last one is current)
+// and their ids
+func testKeyfile(t *testing.T, moments ...time.Time) (*encryption.Keyfile, []string) {
+	content, ids := "", []string{}
+	for _, moment := range moments {
+		line, err := encryption.NewKeyfileLine(moment)
+		assert.NoError(t, err)
+		content += line + "\n"
+		ids = append(ids, strings.Fields(line)[0])
+	}
+
+	path := filepath.Join(t.TempDir(), "documents.keys")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	keyfile, err := encryption.LoadKeyfile(path)
+	assert.NoError(t, err)
+	return keyfile, ids
+}
+
+// testEnvelope — envelope over a temporary keyfile with one fresh key
+func testEnvelope(t *testing.T) *encryption.Envelope {
+	keyfile, _ := testKeyfile(t, time.Now())
+	return encryption.NewEnvelope(keyfile, keyfile.CurrentKeyID())
+}
+
+// capturedArg — matches any value and keeps it, to check what was written
+type capturedArg struct{ value any }
+
+func (a *capturedArg) Match(value any) bool {
+	a.value = value
+	return true
+}
+
+func TestCreateTravelDocument_EncryptsNumber(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	envelope := testEnvelope(t)
+	userID := uuid.New()
+	documentID, encrypted, dataKey := &capturedArg{}, &capturedArg{}, &capturedArg{}
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users u\s+WHERE u.id = \$1 AND u.deleted_at IS NULL\s+FOR UPDATE`).
+		WithArgs(userID, (*uuid.UUID)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"count", "companion_found"}).AddRow(1, true))
+	mock.ExpectQuery(`INSERT INTO travel_documents`).
+		WithArgs(documentID, userID, (*uuid.UUID)(nil), "passport", "DE", (*time.Time)(nil),
+			encrypted, "*****0T47", dataKey, envelope.CurrentKeyID()).
+		WillReturnRows(pgxmock.NewRows(travelDocumentRowColumns).
+			AddRow(uuid.New(), userID, (*uuid.UUID)(nil), "passport", "DE", (*time.Time)(nil), "*****0T47", now, now))
+	mock.ExpectCommit()
+
+	document, err := NewTravelDocumentService(mock, envelope).CreateDocument(context.Background(), userID,
+		models.TravelDocument{Type: "passport", Number: "c01x 00t47", Country: "de"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "*****0T47", document.Number)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// номер хранится только зашифрованным и привязан к id записи
+	number, err := openDocumentNumber(context.Background(), envelope, "travel_documents", documentID.value.(uuid.UUID),
+		encrypted.value.([]byte), encryption.WrappedKey{KeyID: envelope.CurrentKeyID(), Wrapped: dataKey.value.([]byte)})
+	assert.NoError(t, err)
+	assert.Equal(t, "C01X00T47", number)
+	assert.NotContains(t, string(encrypted.value.([]byte)), "C01X00T47")
+}
+
+func TestCreateTravelDocument_Errors(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, companionID := uuid.New(), uuid.New()
+	valid := models.TravelDocument{Type: "id_card", Number: "L01X00T47", Country: "DE", CompanionID: &companionID}
+
+	_, err = NewTravelDocumentService(mock, nil).CreateDocument(context.Background(), userID, valid)
+	assert.ErrorIs(t, err, encryption.ErrNotConfigured)
+
+	service := NewTravelDocumentService(mock, testEnvelope(t))
+	for _, invalid := range []models.TravelDocument{
+		{Type: "visa", Number: "L01X00T47", Country: "DE"},
+		{Type: "passport", Number: "L0-1", Country: "DE"},
+		{Type: "passport", Number: "L01X00T47", Country: "XX"},
+	} {
+		_, err = service.CreateDocument(context.Background(), userID, invalid)
+		assert.ErrorIs(t, err, ErrInvalidTravelDocument, invalid)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs(userID, &companionID).
+		WillReturnRows(pgxmock.NewRows([]string{"count", "companion_found"}).AddRow(0, false))
+	mock.ExpectRollback()
+	_, err = service.CreateDocument(context.Background(), userID, valid)
+	assert.ErrorIs(t, err, ErrCompanionNotFound)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs(userID, &companionID).
+		WillReturnRows(pgxmock.NewRows([]string{"count", "companion_found"}).AddRow(MaxTravelDocuments, true))
+	mock.ExpectRollback()
+	_, err = service.CreateDocument(context.Background(), userID, valid)
+	assert.ErrorIs(t, err, ErrTooManyTravelDocuments)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevealTravelDocument_Audited(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	ctx := context.Background()
+	envelope := testEnvelope(t)
+	userID, documentID, adminID := uuid.New(), uuid.New(), uuid.New()
+	sealed, err := sealDocumentNumber(ctx, envelope, "travel_documents", documentID, "C01X00T47")
+	assert.NoError(t, err)
+	now := time.Now()
+
+	revealRow := func(id uuid.UUID) *pgxmock.Rows {
+		return pgxmock.NewRows(append(travelDocumentRowColumns, "number_encrypted", "data_key", "key_id")).
+			AddRow(id, userID, (*uuid.UUID)(nil), "passport", "DE", (*time.Time)(nil), sealed.masked, now, now,
+				sealed.encrypted, sealed.dataKey.Wrapped, sealed.dataKey.KeyID)
+	}
+	service := NewTravelDocumentService(mock, envelope)
+	audit := models.AuditLog{ActorID: &adminID, Metadata: map[string]any{"reason": "check-in"}}
+
+	_, err = service.RevealDocument(ctx, userID, documentID, " ", audit)
+	assert.ErrorIs(t, err, ErrRevealReasonRequired)
+
+	mock.ExpectQuery(`FROM travel_documents WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(documentID, userID).WillReturnRows(revealRow(documentID))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "travel_document.revealed", "travel_document", &documentID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	document, err := service.RevealDocument(ctx, userID, documentID, "check-in", audit)
+	assert.NoError(t, err)
+	assert.Equal(t, "C01X00T47", document.Number)
+
+	// нет записи в журнале аудита — нет номера
+	mock.ExpectQuery(`FROM travel_documents WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(documentID, userID).WillReturnRows(revealRow(documentID))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "travel_document.revealed", "travel_document", &documentID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(fmt.Errorf("audit_logs is unavailable"))
+
+	document, err = service.RevealDocument(ctx, userID, documentID, "check-in", audit)
+	assert.Error(t, err)
+	assert.Empty(t, document.Number)
+
+	// шифротекст другой записи не расшифровывается
+	otherID := uuid.New()
+	mock.ExpectQuery(`FROM travel_documents WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(otherID, userID).WillReturnRows(revealRow(otherID))
+
+	_, err = service.RevealDocument(ctx, userID, otherID, "check-in", audit)
+	assert.ErrorIs(t, err, encryption.ErrDecrypt)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevealTravelDocument_MovedFromCompanion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	ctx := context.Background()
+	envelope := testEnvelope(t)
+	userID, companionID := uuid.New(), uuid.New()
+	// номер, зашифрованный ещё в user_companions: V26 перенесла документ с id компаньона
+	sealed, err := sealDocumentNumber(ctx, envelope, "user_companions", companionID, "L01X00T47")
+	assert.NoError(t, err)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM travel_documents WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(companionID, userID).
+		WillReturnRows(pgxmock.NewRows(append(travelDocumentRowColumns, "number_encrypted", "data_key", "key_id")).
+			AddRow(companionID, userID, &companionID, "id_card", "DE", (*time.Time)(nil), sealed.masked, now, now,
+				sealed.encrypted, sealed.dataKey.Wrapped, sealed.dataKey.KeyID))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(pgxmock.AnyArg(), "travel_document.revealed", "travel_document", &companionID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	document, err := NewTravelDocumentService(mock, envelope).RevealDocument(ctx, userID, companionID, "check-in", models.AuditLog{})
+	assert.NoError(t, err)
+	assert.Equal(t, "L01X00T47", document.Number)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateKeys(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	ctx := context.Background()
+	keyfile, ids := testKeyfile(t, time.Now().Add(-time.Hour), time.Now())
+	envelope := encryption.NewEnvelope(keyfile, keyfile.CurrentKeyID())
+	documentID := uuid.New()
+
+	// документ, сохранённый до добавления нового мастер-ключа
+	sealed, err := sealDocumentNumber(ctx, encryption.NewEnvelope(keyfile, ids[0]), "travel_documents", documentID, "C01X00T47")
+	assert.NoError(t, err)
+	rewrapped := &capturedArg{}
+
+	mock.ExpectQuery(`SELECT id, data_key, key_id FROM travel_documents\s+WHERE key_id <> \$2`).
+		WithArgs(2, ids[1]).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "data_key", "key_id"}).AddRow(documentID, sealed.dataKey.Wrapped, ids[0]))
+	mock.ExpectExec(`UPDATE travel_documents SET data_key = \$3, key_id = \$4\s+WHERE id = \$1 AND key_id = \$2`).
+		WithArgs(documentID, ids[0], rewrapped, ids[1]).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	report, err := NewTravelDocumentService(mock, envelope).RotateKeys(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, KeyRotationReport{Rewrapped: 1}, report)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// зашифрованный номер не менялся и открывается ключом, обёрнутым текущим мастер-ключом
+	number, err := openDocumentNumber(ctx, envelope, "travel_documents", documentID, sealed.encrypted,
+		encryption.WrappedKey{KeyID: ids[1], Wrapped: rewrapped.value.([]byte)})
+	assert.NoError(t, err)
+	assert.Equal(t, "C01X00T47", number)
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+const (
+	// MaxFavoriteHotels — favorites per user
+	MaxFavoriteHotels = 100
+	// MaxPreferredCities — preferred cities per user
+	MaxPreferredCities = 20
+	// maxHotelIDLength — the user_favorite_hotels.hotel_id column size
+	maxHotelIDLength = 64
+)
+
+var (
+	// ErrInvalidTravelPreferences - a travel preference failed validation, wraps the reason
+	ErrInvalidTravelPreferences = errors.New("invalid travel preferences")
+	// ErrUnknownHotel - the hotel ID is not known to hotels-service
+	ErrUnknownHotel = errors.New("unknown hotel")
+	// ErrHotelServiceUnavailable - hotels-service could not be asked, wraps the cause
+	ErrHotelServiceUnavailable = errors.New("hotel service unavailable")
+	ErrTooManyFavorites        = fmt.Errorf("at most %d favorite hotels are allowed", MaxFavoriteHotels)
+	ErrFavoriteNotFound        = errors.New("favorite hotel not found")
+)
+
+// HotelCatalog — the part of hotels-service favorites, travel preferences and recommendations rely on
+type HotelCatalog interface {
+	GetHotels() ([]external_services.Hotel, error)
+	GetHotel(id string) (external_services.Hotel, error)
+	GetLocations() ([]external_services.LocationCountry, error)
+}
+
+const travelPreferenceColumns = `preferred_city_ids, room_type, budget_min, budget_max, currency, updated_at`
+
+// TravelService — favorite hotels and travel preferences of users
+type TravelService struct {
+	db     db_interface
+	hotels HotelCatalog
+}
+
+// NewTravelService creates new service
+func NewTravelService(db db_interface, hotels HotelCatalog) *TravelService {
+	return &TravelService{db: db, hotels: hotels}
+}
+
+// ListFavorites - favorite hotels of an active user, newest first
+func (s *TravelService) ListFavorites(ctx context.Context, userID uuid.UUID) ([]models.FavoriteHotel, error) {
+	if err := ensureActiveUser(ctx, s.db, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT hotel_id, created_at FROM user_favorite_hotels
+		WHERE user_id = $1
+		ORDER BY created_at DESC, hotel_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := []models.FavoriteHotel{}
+	for rows.Next() {
+		var favorite models.FavoriteHotel
+		if err := rows.Scan(&favorite.HotelID, &favorite.CreatedAt); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, favorite)
+	}
+	return favorites, rows.Err()
+}
+
+// AddFavorite - marks a hotel known to hotels-service as favorite; idempotent,
+// created is false when it already was one
+func (s *TravelService) AddFavorite(ctx context.Context, userID uuid.UUID, hotelID string) (favorite models.FavoriteHotel, created bool, err error) {
+	hotelID = strings.TrimSpace(hotelID)
+	if err := s.checkHotel(hotelID); err != nil {
+		return models.FavoriteHotel{}, false, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.FavoriteHotel{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the users row lock serializes concurrent adds, so the limit holds
+	var count int
+	var existing *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM user_favorite_hotels WHERE user_id = u.id),
+			(SELECT created_at FROM user_favorite_hotels WHERE user_id = u.id AND hotel_id = $2)
+		FROM users u
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+		FOR UPDATE`, userID, hotelID).Scan(&count, &existing)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.FavoriteHotel{}, false, errors.New("user not found")
+		}
+		return models.FavoriteHotel{}, false, err
+	}
+	if existing != nil {
+		return models.FavoriteHotel{HotelID: hotelID, CreatedAt: *existing}, false, nil
+	}
+	if count >= MaxFavoriteHotels {
+		return models.FavoriteHotel{}, false, ErrTooManyFavorites
+	}
+
+	favorite = models.FavoriteHotel{HotelID: hotelID}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_favorite_hotels (user_id, hotel_id) VALUES ($1, $2)
+		RETURNING created_at`, userID, hotelID).Scan(&favorite.CreatedAt)
+	if err != nil {
+		return models.FavoriteHotel{}, false, err
+	}
+
+	return favorite, true, tx.Commit(ctx)
+}
+
+// RemoveFavorite - unmarks a favorite hotel, ErrFavoriteNotFound when it was none
+func (s *TravelService) RemoveFavorite(ctx context.Context, userID uuid.UUID, hotelID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM user_favorite_hotels WHERE user_id = $1 AND hotel_id = $2`, userID, hotelID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFavoriteNotFound
+	}
+	return nil
+}
+
+// GetTravelPreferences - travel preferences of an active user, empty when never set
+func (s *TravelService) GetTravelPreferences(ctx context.Context, userID uuid.UUID) (models.TravelPreferences, error) {
+	query := `
+		SELECT ` + prefixColumns("p.", travelPreferenceColumns) + `
+		FROM users u
+		LEFT JOIN user_travel_preferences p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL`
+
+	preferences, err := scanTravelPreferences(s.db.QueryRow(ctx, query, userID), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TravelPreferences{}, errors.New("user not found")
+		}
+		return models.TravelPreferences{}, err
+	}
+	return preferences, nil
+}
+
+// ReplaceTravelPreferences - replaces all travel preferences, omitted ones are cleared.
+// Cities must exist in hotels-service locations, the currency is required with a budget.
+func (s *TravelService) ReplaceTravelPreferences(ctx context.Context, userID uuid.UUID, preferences models.TravelPreferences) (models.TravelPreferences, error) {
+	if err := s.normalizeTravelPreferences(&preferences); err != nil {
+		return models.TravelPreferences{}, err
+	}
+
+	// SELECT from users: deleted users cannot get preferences, no row means not found
+	query := `
+		INSERT INTO user_travel_preferences (user_id, preferred_city_ids, room_type, budget_min, budget_max, currency)
+		SELECT id, $2, $3, $4, $5, $6 FROM users WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_id) DO UPDATE SET preferred_city_ids = EXCLUDED.preferred_city_ids,
+			room_type = EXCLUDED.room_type, budget_min = EXCLUDED.budget_min, budget_max = EXCLUDED.budget_max,
+			currency = EXCLUDED.currency, updated_at = NOW()
+		RETURNING ` + travelPreferenceColumns
+
+	saved, err := scanTravelPreferences(s.db.QueryRow(ctx, query, userID, preferences.PreferredCityIDs,
+		preferences.RoomType, preferences.BudgetMin, preferences.BudgetMax, preferences.Currency), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TravelPreferences{}, errors.New("user not found")
+		}
+		return models.TravelPreferences{}, err
+	}
+	return saved, nil
+}
+
+// checkHotel — ErrUnknownHotel unless hotels-service knows the hotel
+func (s *TravelService) checkHotel(hotelID string) error {
+	if hotelID == "" || len(hotelID) > maxHotelIDLength {
+		return fmt.Errorf("%w: hotel_id must be 1 to %d characters", ErrUnknownHotel, maxHotelIDLength)
+	}
+
+	if _, err := s.hotels.GetHotel(hotelID); err != nil {
+		if errors.Is(err, external_services.ErrHotelNotFound) {
+			return ErrUnknownHotel
+		}
+		return fmt.Errorf("%w: %w", ErrHotelServiceUnavailable, err)
+	}
+	return nil
+}
+
+// normalizeTravelPreferences — validates in place: deduplicated known cities, room type,
+// budget range and its currency
+func (s *TravelService) normalizeTravelPreferences(preferences *models.TravelPreferences) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidTravelPreferences, fmt.Sprintf(format, args...))
+	}
+
+	cities := make([]uuid.UUID, 0, len(preferences.PreferredCityIDs))
+	for _, id := range preferences.PreferredCityIDs {
+		if !slices.Contains(cities, id) {
+			cities = append(cities, id)
+		}
+	}
+	if len(cities) > MaxPreferredCities {
+		return invalid("at most %d preferred cities are allowed", MaxPreferredCities)
+	}
+	if len(cities) > 0 {
+		known, err := s.knownCityIDs()
+		if err != nil {
+			return err
+		}
+		for _, id := range cities {
+			if !known[id.String()] {
+				return invalid("unknown city %s", id)
+			}
+		}
+	}
+	preferences.PreferredCityIDs = cities
+
+	if preferences.RoomType != nil && !slices.Contains(models.RoomTypes, *preferences.RoomType) {
+		return invalid("room_type must be one of %s", strings.Join(models.RoomTypes, ", "))
+	}
+
+	minimum, maximum := preferences.BudgetMin, preferences.BudgetMax
+	if (minimum != nil && *minimum < 0) || (maximum != nil && *maximum < 0) {
+		return invalid("budget must not be negative")
+	}
+	if minimum != nil && maximum != nil && *minimum > *maximum {
+		return invalid("budget_min must not exceed budget_max")
+	}
+
+	if preferences.Currency != nil {
+		currency, err := utils.NormalizeCurrency(*preferences.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTravelPreferences, err)
+		}
+		preferences.Currency = &currency
+	} else if minimum != nil || maximum != nil {
+		return invalid("currency is required with a budget")
+	}
+
+	return nil
+}
+
+// knownCityIDs — IDs of every city of hotels-service locations
+func (s *TravelService) knownCityIDs() (map[string]bool, error) {
+	countries, err := s.hotels.GetLocations()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrHotelServiceUnavailable, err)
+	}
+
+	known := map[string]bool{}
+	for _, country := range countries {
+		for _, city := range country.Cities {
+			known[city.ID] = true
+		}
+	}
+	return known, nil
+}
+
+// ensureActiveUser — "user not found" unless the user exists and is not deleted
+func ensureActiveUser(ctx context.Context, db db_interface, userID uuid.UUID) error {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// scanTravelPreferences — scans travelPreferenceColumns, no preferred cities is an empty list
+func scanTravelPreferences(row pgx.Row, userID uuid.UUID) (models.TravelPreferences, error) {
+	preferences := models.TravelPreferences{UserID: userID}
+	if err := row.Scan(&preferences.PreferredCityIDs, &preferences.RoomType, &preferences.BudgetMin,
+		&preferences.BudgetMax, &preferences.Currency, &preferences.UpdatedAt); err != nil {
+		return models.TravelPreferences{}, err
+	}
+	if preferences.PreferredCityIDs == nil {
+		preferences.PreferredCityIDs = []uuid.UUID{}
+	}
+	return preferences, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+)
+
+// fakeHotelCatalog — hotels-service with a fixed set of hotels and locations
+type fakeHotelCatalog struct {
+	hotels    map[string]external_services.Hotel
+	locations []external_services.LocationCountry
+	err       error
+}
+
+func (f *fakeHotelCatalog) GetHotels() ([]external_services.Hotel, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	hotels := make([]external_services.Hotel, 0, len(f.hotels))
+	for _, hotel := range f.hotels {
+		hotels = append(hotels, hotel)
+	}
+	return hotels, nil
+}
+
+func (f *fakeHotelCatalog) GetHotel(id string) (external_services.Hotel, error) {
+	if f.err != nil {
+		return external_services.Hotel{}, f.err
+	}
+	hotel, ok := f.hotels[id]
+	if !ok {
+		return external_services.Hotel{}, external_services.ErrHotelNotFound
+	}
+	return hotel, nil
+}
+
+func (f *fakeHotelCatalog) GetLocations() ([]external_services.LocationCountry, error) {
+	return f.locations, f.err
+}
+
+var travelPreferenceRowColumns = []string{"preferred_city_ids", "room_type", "budget_min", "budget_max", "currency", "updated_at"}
+
+func TestAddFavorite(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+	catalog := &fakeHotelCatalog{hotels: map[string]external_services.Hotel{"h-1": {ID: "h-1", City: "Vienna"}}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users u\s+WHERE u.id = \$1 AND u.deleted_at IS NULL\s+FOR UPDATE`).
+		WithArgs(userID, "h-1").
+		WillReturnRows(pgxmock.NewRows([]string{"count", "created_at"}).AddRow(3, (*time.Time)(nil)))
+	mock.ExpectQuery(`INSERT INTO user_favorite_hotels \(user_id, hotel_id\) VALUES \(\$1, \$2\)`).
+		WithArgs(userID, "h-1").
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(now))
+	mock.ExpectCommit()
+
+	favorite, created, err := NewTravelService(mock, catalog).AddFavorite(context.Background(), userID, " h-1 ")
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, models.FavoriteHotel{HotelID: "h-1", CreatedAt: now}, favorite)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddFavorite_AlreadyFavoriteAndLimit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	addedAt := time.Now().Add(-time.Hour)
+	service := NewTravelService(mock, &fakeHotelCatalog{hotels: map[string]external_services.Hotel{"h-1": {}, "h-2": {}}})
+
+	// повторное добавление ничего не пишет
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs(userID, "h-1").
+		WillReturnRows(pgxmock.NewRows([]string{"count", "created_at"}).AddRow(1, &addedAt))
+	mock.ExpectRollback()
+
+	favorite, created, err := service.AddFavorite(context.Background(), userID, "h-1")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, addedAt, favorite.CreatedAt)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs(userID, "h-2").
+		WillReturnRows(pgxmock.NewRows([]string{"count", "created_at"}).AddRow(MaxFavoriteHotels, (*time.Time)(nil)))
+	mock.ExpectRollback()
+
+	_, _, err = service.AddFavorite(context.Background(), userID, "h-2")
+	assert.ErrorIs(t, err, ErrTooManyFavorites)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddFavorite_ValidatesHotel(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+
+	_, _, err = NewTravelService(mock, &fakeHotelCatalog{}).AddFavorite(context.Background(), userID, "missing")
+	assert.ErrorIs(t, err, ErrUnknownHotel)
+
+	_, _, err = NewTravelService(mock, &fakeHotelCatalog{}).AddFavorite(context.Background(), userID, "")
+	assert.ErrorIs(t, err, ErrUnknownHotel)
+
+	_, _, err = NewTravelService(mock, &fakeHotelCatalog{err: errors.New("connection refused")}).
+		AddFavorite(context.Background(), userID, "h-1")
+	assert.ErrorIs(t, err, ErrHotelServiceUnavailable)
+
+	// без валидного отеля в базу не ходим
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveFavorite_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectExec(`DELETE FROM user_favorite_hotels WHERE user_id = \$1 AND hotel_id = \$2`).
+		WithArgs(userID, "h-1").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = NewTravelService(mock, &fakeHotelCatalog{}).RemoveFavorite(context.Background(), userID, "h-1")
+
+	assert.ErrorIs(t, err, ErrFavoriteNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceTravelPreferences_Normalizes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, vienna, graz := uuid.New(), uuid.New(), uuid.New()
+	catalog := &fakeHotelCatalog{locations: []external_services.LocationCountry{{
+		ID: uuid.NewString(), Code: "AT",
+		Cities: []external_services.LocationCity{{ID: vienna.String()}, {ID: graz.String()}},
+	}}}
+	roomType, budgetMin, budgetMax, currency := models.RoomTypeDouble, 80, 150, "EUR"
+	updatedAt := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO user_travel_preferences \(user_id, preferred_city_ids, room_type, budget_min, budget_max, currency\)\s+`+
+		`SELECT id, \$2, \$3, \$4, \$5, \$6 FROM users WHERE id = \$1 AND deleted_at IS NULL\s+ON CONFLICT \(user_id\) DO UPDATE`).
+		WithArgs(userID, []uuid.UUID{vienna, graz}, &roomType, &budgetMin, &budgetMax, &currency).
+		WillReturnRows(pgxmock.NewRows(travelPreferenceRowColumns).
+			AddRow([]uuid.UUID{vienna, graz}, &roomType, &budgetMin, &budgetMax, &currency, &updatedAt))
+
+	lowerCurrency := "eur"
+	preferences, err := NewTravelService(mock, catalog).ReplaceTravelPreferences(context.Background(), userID, models.TravelPreferences{
+		PreferredCityIDs: []uuid.UUID{vienna, graz, vienna},
+		RoomType:         &roomType,
+		BudgetMin:        &budgetMin,
+		BudgetMax:        &budgetMax,
+		Currency:         &lowerCurrency,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, preferences.UserID)
+	assert.Equal(t, []uuid.UUID{vienna, graz}, preferences.PreferredCityIDs)
+	assert.Equal(t, "EUR", *preferences.Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceTravelPreferences_Validation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	service := NewTravelService(mock, &fakeHotelCatalog{})
+	suite, castle := models.RoomTypeSuite, "castle"
+	low, high, negative := 50, 200, -1
+	usd, btc := "USD", "BTC"
+
+	for name, preferences := range map[string]models.TravelPreferences{
+		"unknown city":        {PreferredCityIDs: []uuid.UUID{uuid.New()}},
+		"unknown room type":   {RoomType: &castle},
+		"negative budget":     {BudgetMin: &negative, Currency: &usd},
+		"inverted range":      {BudgetMin: &high, BudgetMax: &low, Currency: &usd},
+		"budget w/o currency": {RoomType: &suite, BudgetMax: &high},
+		"bad currency":        {Currency: &btc},
+	} {
+		_, err := service.ReplaceTravelPreferences(context.Background(), uuid.New(), preferences)
+		assert.ErrorIs(t, err, ErrInvalidTravelPreferences, name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTravelPreferences_EmptyWhenUnset(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery(`FROM users u\s+LEFT JOIN user_travel_preferences p ON p.user_id = u.id`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(travelPreferenceRowColumns).
+			AddRow(([]uuid.UUID)(nil), (*string)(nil), (*int)(nil), (*int)(nil), (*string)(nil), (*time.Time)(nil)))
+
+	preferences, err := NewTravelService(mock, &fakeHotelCatalog{}).GetTravelPreferences(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.TravelPreferences{UserID: userID, PreferredCityIDs: []uuid.UUID{}}, preferences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// MaxBatchGetIDs — most IDs one batchGet request may ask for
+const MaxBatchGetIDs = 100
+
+// GetUsersByIDs - many users in one query, reading only the columns behind the given fields
+// (all when empty); unknown and deleted IDs are absent, the order is unspecified
+func (s *UserService) GetUsersByIDs(ids []uuid.UUID, fields []string) ([]models.User, error) {
+	columns, err := userColumnsFor(fields, "id", "version")
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []models.User{}, nil
+	}
+
+	query := `
+		SELECT ` + strings.Join(columns, ", ") + `
+		FROM users
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := s.db.Query(context.Background(), query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, len(ids))
+	for rows.Next() {
+		user, err := scanUserColumns(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
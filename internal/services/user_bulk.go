@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// MaxBulkUsers — most users one bulk operation may touch, by IDs or by filter
+const MaxBulkUsers = 1000
+
+var (
+	// ErrBulkTooLarge - the selector matches more than MaxBulkUsers users
+	ErrBulkTooLarge = fmt.Errorf("bulk operation is limited to %d users", MaxBulkUsers)
+	// ErrBulkNoChanges - bulk update without any field to set
+	ErrBulkNoChanges = errors.New("no changes requested")
+	// ErrInvalidSelector - neither or both of IDs and filter, or a filter that would match every user
+	ErrInvalidSelector = errors.New("exactly one of ids or a non-empty filter is required")
+)
+
+// bulkTarget — locked user row of a bulk operation
+type bulkTarget struct {
+	id   uuid.UUID
+	role string
+}
+
+// bulkChange — what a bulk operation does to one locked user: the item status,
+// the audit entry when something changed
+type bulkChange func(target bulkTarget) (models.BulkUserItem, *models.AuditLog)
+
+// BulkUpdateUsers - applies changes to every selected user in one transaction,
+// one audit entry per changed user; audit carries the actor, IP and request metadata
+func (s *UserService) BulkUpdateUsers(ctx context.Context, selector models.BulkUserSelector, changes models.BulkUserChanges, audit models.AuditLog) (models.BulkUserReport, error) {
+	if changes.Role == nil {
+		return models.BulkUserReport{}, ErrBulkNoChanges
+	}
+	role := *changes.Role
+
+	return s.runBulk(ctx, selector, audit, func(target bulkTarget) (models.BulkUserItem, *models.AuditLog) {
+		if target.role == role {
+			return models.BulkUserItem{ID: target.id, Status: models.BulkItemUnchanged}, nil
+		}
+		entry := bulkAuditEntry(audit, models.AuditActionUserRoleChanged, target.id)
+		entry.Metadata["from"] = target.role
+		entry.Metadata["to"] = role
+		return models.BulkUserItem{ID: target.id, Status: models.BulkItemUpdated}, &entry
+	}, func(tx pgx.Tx, ids []uuid.UUID) error {
+		// tokens issued with the old role stop working, see AuthService.IsTokenRevoked
+		_, err := tx.Exec(ctx, `UPDATE users SET role = $1, updated_at = NOW() WHERE id = ANY($2)`, role, ids)
+		return err
+	})
+}
+
+// BulkDeleteUsers - soft-deletes every selected user in one transaction, one audit entry per user
+func (s *UserService) BulkDeleteUsers(ctx context.Context, selector models.BulkUserSelector, audit models.AuditLog) (models.BulkUserReport, error) {
+	return s.runBulk(ctx, selector, audit, func(target bulkTarget) (models.BulkUserItem, *models.AuditLog) {
+		entry := bulkAuditEntry(audit, models.AuditActionUserDeleted, target.id)
+		return models.BulkUserItem{ID: target.id, Status: models.BulkItemDeleted}, &entry
+	}, func(tx pgx.Tx, ids []uuid.UUID) error {
+		_, err := tx.Exec(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = ANY($1)`, ids)
+		return err
+	})
+}
+
+// runBulk — locks the selected users, decides per user, applies the change with one statement
+// for all changed users and writes their audit entries; everything or nothing is committed.
+// The actor's own account is never touched, so an admin cannot lock themselves out
+func (s *UserService) runBulk(
+	ctx context.Context,
+	selector models.BulkUserSelector,
+	audit models.AuditLog,
+	change bulkChange,
+	apply func(tx pgx.Tx, ids []uuid.UUID) error,
+) (models.BulkUserReport, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.BulkUserReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	order, targets, err := lockBulkTargets(ctx, tx, selector)
+	if err != nil {
+		return models.BulkUserReport{}, err
+	}
+
+	report := models.BulkUserReport{Items: make([]models.BulkUserItem, 0, len(order))}
+	var changedIDs []uuid.UUID
+	var entries []models.AuditLog
+
+	for _, id := range order {
+		target, found := targets[id]
+		var item models.BulkUserItem
+		var entry *models.AuditLog
+
+		switch {
+		case !found:
+			item = models.BulkUserItem{ID: id, Status: models.BulkItemNotFound}
+		case audit.ActorID != nil && *audit.ActorID == id:
+			item = models.BulkUserItem{ID: id, Status: models.BulkItemFailed, Reason: "own account cannot be changed in bulk"}
+		default:
+			item, entry = change(target)
+		}
+
+		switch item.Status {
+		case models.BulkItemNotFound:
+			report.NotFound++
+		case models.BulkItemFailed:
+			report.Failed++
+		case models.BulkItemUnchanged:
+			report.Unchanged++
+		default:
+			report.Changed++
+		}
+		if entry != nil {
+			changedIDs = append(changedIDs, id)
+			entries = append(entries, *entry)
+		}
+		report.Items = append(report.Items, item)
+	}
+	report.Total = len(report.Items)
+
+	if len(changedIDs) > 0 {
+		if err := apply(tx, changedIDs); err != nil {
+			return models.BulkUserReport{}, err
+		}
+	}
+	for _, entry := range entries {
+		if err := insertAuditLog(ctx, tx, entry); err != nil {
+			return models.BulkUserReport{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.BulkUserReport{}, err
+	}
+	return report, nil
+}
+
+// lockBulkTargets — selected active users locked FOR UPDATE; order is the request order for IDs
+// (unknown ones included, absent from the map) and ID order for a filter
+func lockBulkTargets(ctx context.Context, tx pgx.Tx, selector models.BulkUserSelector) ([]uuid.UUID, map[uuid.UUID]bulkTarget, error) {
+	var query string
+	var args []any
+
+	switch {
+	case selector.Filter != nil && selector.IDs == nil:
+		// deleted users are never a bulk target, an empty filter would select everybody
+		filter := *selector.Filter
+		if filter.IncludeDeleted || filter.OnlyDeleted || filter == (models.UserFilter{}) {
+			return nil, nil, ErrInvalidSelector
+		}
+		conditions := buildUserFilter(filter, &args)
+		args = append(args, MaxBulkUsers+1)
+		query = fmt.Sprintf(`
+			SELECT id, role
+			FROM users
+			WHERE %s
+			ORDER BY id
+			LIMIT $%d
+			FOR UPDATE`, strings.Join(conditions, " AND "), len(args))
+	case selector.Filter == nil && len(selector.IDs) > 0:
+		if len(selector.IDs) > MaxBulkUsers {
+			return nil, nil, ErrBulkTooLarge
+		}
+		args = []any{selector.IDs}
+		query = `
+			SELECT id, role
+			FROM users
+			WHERE id = ANY($1) AND deleted_at IS NULL
+			ORDER BY id
+			FOR UPDATE`
+	default:
+		return nil, nil, ErrInvalidSelector
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	targets := map[uuid.UUID]bulkTarget{}
+	var matched []uuid.UUID
+	for rows.Next() {
+		var target bulkTarget
+		if err := rows.Scan(&target.id, &target.role); err != nil {
+			return nil, nil, err
+		}
+		targets[target.id] = target
+		matched = append(matched, target.id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if selector.Filter != nil {
+		if len(matched) > MaxBulkUsers {
+			return nil, nil, ErrBulkTooLarge
+		}
+		return matched, targets, nil
+	}
+	return selector.IDs, targets, nil
+}
+
+// bulkAuditEntry — per-user copy of the request audit data
+func bulkAuditEntry(audit models.AuditLog, action string, userID uuid.UUID) models.AuditLog {
+	entry := audit
+	entry.Action = action
+	entry.TargetType = models.AuditTargetUser
+	entry.TargetID = &userID
+	entry.Metadata = maps.Clone(audit.Metadata)
+	if entry.Metadata == nil {
+		entry.Metadata = map[string]any{}
+	}
+	entry.Metadata["bulk"] = true
+	return entry
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+func TestBulkUpdateUsers_PerItemResult(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	adminID, promoted, alreadyAdmin, unknown := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ids := []uuid.UUID{promoted, adminID, unknown, alreadyAdmin}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, role\s+FROM users\s+WHERE id = ANY\(\$1\) AND deleted_at IS NULL\s+ORDER BY id\s+FOR UPDATE`).
+		WithArgs(ids).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "role"}).
+			AddRow(promoted, "user").
+			AddRow(adminID, "admin").
+			AddRow(alreadyAdmin, "admin"))
+	// одно UPDATE на все изменённые строки, аудит — на каждую
+	mock.ExpectExec(`UPDATE users SET role = \$1, updated_at = NOW\(\) WHERE id = ANY\(\$2\)`).
+		WithArgs("admin", []uuid.UUID{promoted}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "user.role_changed", "user", &promoted, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	role := "admin"
+	report, err := NewUserServiceInterface(mock, nil).BulkUpdateUsers(context.Background(),
+		models.BulkUserSelector{IDs: ids}, models.BulkUserChanges{Role: &role}, models.AuditLog{ActorID: &adminID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BulkUserItem{
+		{ID: promoted, Status: models.BulkItemUpdated},
+		{ID: adminID, Status: models.BulkItemFailed, Reason: "own account cannot be changed in bulk"},
+		{ID: unknown, Status: models.BulkItemNotFound},
+		{ID: alreadyAdmin, Status: models.BulkItemUnchanged},
+	}, report.Items)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Changed)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, 1, report.NotFound)
+	assert.Equal(t, 1, report.Failed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkDeleteUsers_ByFilter(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	first, second := uuid.New(), uuid.New()
+	role := "user"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, role\s+FROM users\s+WHERE deleted_at IS NULL AND role = \$1\s+ORDER BY id\s+LIMIT \$2\s+FOR UPDATE`).
+		WithArgs("user", MaxBulkUsers+1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "role"}).
+			AddRow(first, "user").
+			AddRow(second, "user"))
+	mock.ExpectExec(`UPDATE users SET deleted_at = NOW\(\) WHERE id = ANY\(\$1\)`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	for _, id := range []uuid.UUID{first, second} {
+		mock.ExpectExec(`INSERT INTO audit_logs`).
+			WithArgs(pgxmock.AnyArg(), "user.deleted", "user", &id, pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+
+	report, err := NewUserServiceInterface(mock, nil).BulkDeleteUsers(context.Background(),
+		models.BulkUserSelector{Filter: &models.UserFilter{Role: &role}}, models.AuditLog{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Changed)
+	assert.Equal(t, models.BulkItemDeleted, report.Items[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkDeleteUsers_FilterMatchesTooMany(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	rows := pgxmock.NewRows([]string{"id", "role"})
+	for i := 0; i <= MaxBulkUsers; i++ {
+		rows.AddRow(uuid.New(), "user")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WithArgs("user", MaxBulkUsers+1).WillReturnRows(rows)
+	// ничего не изменено — транзакция откатывается
+	mock.ExpectRollback()
+
+	role := "user"
+	_, err = NewUserServiceInterface(mock, nil).BulkDeleteUsers(context.Background(),
+		models.BulkUserSelector{Filter: &models.UserFilter{Role: &role}}, models.AuditLog{})
+
+	assert.ErrorIs(t, err, ErrBulkTooLarge)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkUpdateUsers_InvalidInput(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	service := NewUserServiceInterface(mock, nil)
+	role := "user"
+
+	_, err = service.BulkUpdateUsers(context.Background(),
+		models.BulkUserSelector{IDs: []uuid.UUID{uuid.New()}}, models.BulkUserChanges{}, models.AuditLog{})
+	assert.ErrorIs(t, err, ErrBulkNoChanges)
+
+	// пустой фильтр выбрал бы всех пользователей
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	_, err = service.BulkUpdateUsers(context.Background(),
+		models.BulkUserSelector{Filter: &models.UserFilter{}}, models.BulkUserChanges{Role: &role}, models.AuditLog{})
+	assert.ErrorIs(t, err, ErrInvalidSelector)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
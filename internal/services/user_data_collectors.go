@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// userDataCollector — gathers one section of the GDPR export archive (<name>.json)
+type userDataCollector struct {
+	name    string
+	collect func(ctx context.Context, db db_interface, userID uuid.UUID) (any, error)
+}
+
+// userDataCollectors — everything the export contains, add new personal data here
+// (and to userDataErasers). Secrets such as password hashes and tokens are never exported.
+var userDataCollectors = []userDataCollector{
+	{name: "user", collect: collectUserRow},
+	{name: "profile", collect: collectOne(`
+		SELECT phone, phone_verified_at, address, bio, avatar_url, created_at, updated_at
+		FROM user_profiles WHERE user_id = $1`)},
+	{name: "preferences", collect: collectOne(`
+		SELECT language, currency, timezone, units, created_at, updated_at
+		FROM user_preferences WHERE user_id = $1`)},
+	{name: "travel_preferences", collect: collectOne(`
+		SELECT preferred_city_ids, room_type, budget_min, budget_max, currency, created_at, updated_at
+		FROM user_travel_preferences WHERE user_id = $1`)},
+	{name: "favorite_hotels", collect: collectAll(`
+		SELECT hotel_id, created_at
+		FROM user_favorite_hotels WHERE user_id = $1
+		ORDER BY created_at`)},
+	{name: "companions", collect: collectAll(`
+		SELECT id::text AS id, first_name, last_name, birth_date, relationship, created_at, updated_at
+		FROM user_companions WHERE user_id = $1
+		ORDER BY created_at`)},
+	{name: "travel_documents", collect: collectAll(`
+		SELECT id::text AS id, companion_id::text AS companion_id, type, number_masked, country, expires_on,
+			created_at, updated_at
+		FROM travel_documents WHERE user_id = $1
+		ORDER BY created_at`)},
+	{name: "sessions", collect: collectAll(`
+		SELECT id::text AS id, provider, redirect_uri, expires_at, created_at
+		FROM oauth_sessions WHERE user_id = $1
+		ORDER BY created_at`)},
+	{name: "identities", collect: collectAll(`
+		SELECT DISTINCT provider, provider_id
+		FROM oauth_sessions WHERE user_id = $1
+		ORDER BY provider, provider_id`)},
+	{name: "consents", collect: collectConsents},
+	{name: "security_events", collect: collectAll(`
+		SELECT id::text AS id, event_type, ip_address, user_agent, metadata, created_at
+		FROM security_events WHERE user_id = $1
+		ORDER BY created_at`)},
+}
+
+// collectUserData — runs every collector, section name -> data
+func collectUserData(ctx context.Context, db db_interface, userID uuid.UUID) (map[string]any, error) {
+	sections := make(map[string]any, len(userDataCollectors))
+	for _, collector := range userDataCollectors {
+		data, err := collector.collect(ctx, db, userID)
+		if err != nil {
+			return nil, err
+		}
+		sections[collector.name] = data
+	}
+	return sections, nil
+}
+
+func collectUserRow(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+	query := `SELECT` + userSelectColumns + ` FROM users WHERE id = $1`
+	return scanUser(db.QueryRow(ctx, query, userID))
+}
+
+// collectConsents — the opt-ins as consent records, defaults are marked as not explicitly given
+func collectConsents(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+	var consentsUpdatedAt *time.Time
+	granted := make([]*bool, len(consentColumns))
+	targets := []any{&consentsUpdatedAt}
+	for i := range granted {
+		targets = append(targets, &granted[i])
+	}
+
+	query := `SELECT consents_updated_at, ` + strings.Join(consentColumns, ", ") + `
+			  FROM user_preferences WHERE user_id = $1`
+	err := db.QueryRow(ctx, query, userID).Scan(targets...)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	defaults := map[string]bool{
+		"marketing_emails": models.DefaultUserPreferences.MarketingEmails,
+		"notify_email":     models.DefaultUserPreferences.NotifyEmail,
+		"notify_sms":       models.DefaultUserPreferences.NotifySMS,
+		"notify_push":      models.DefaultUserPreferences.NotifyPush,
+	}
+
+	consents := make([]models.UserConsent, 0, len(consentColumns))
+	for i, purpose := range consentColumns {
+		consent := models.UserConsent{Purpose: purpose, Granted: defaults[purpose]}
+		if granted[i] != nil {
+			consent.Granted = *granted[i]
+			consent.Explicit = true
+			consent.UpdatedAt = consentsUpdatedAt
+		}
+		consents = append(consents, consent)
+	}
+	return consents, nil
+}
+
+// collectAll — every row of a query with $1 = user ID, as column -> value maps
+func collectAll(query string) func(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+	return func(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+		rows, err := db.Query(ctx, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToMap)
+	}
+}
+
+// collectOne — like collectAll for a single optional row, nil when there is none
+func collectOne(query string) func(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+	return func(ctx context.Context, db db_interface, userID uuid.UUID) (any, error) {
+		rows, err := db.Query(ctx, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		all, err := pgx.CollectRows(rows, pgx.RowToMap)
+		if err != nil || len(all) == 0 {
+			return nil, err
+		}
+		return all[0], nil
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
+)
+
+var ErrUserAlreadyErased = errors.New("user is already erased")
+
+// userDataEraser — removes or anonymizes one kind of personal data of a user inside the erase transaction.
+// Files kept outside the database use media instead of erase: it returns their URLs, the files are
+// deleted from media storage only after the transaction commits.
+type userDataEraser struct {
+	name  string
+	erase func(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error
+	media func(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]string, error)
+}
+
+// userDataErasers — everything EraseUser wipes, add new personal data here.
+// The users row goes last: it stays as a non-PII tombstone so bookings keep valid references.
+// avatar_files reads the avatar URL before user_profiles clears it.
+var userDataErasers = []userDataEraser{
+	{name: "avatar_files", media: userAvatarURLs},
+	{name: "user_profiles", erase: eraseUserProfile},
+	{name: "user_preferences", erase: execForUser(`DELETE FROM user_preferences WHERE user_id = $1`)},
+	{name: "user_travel_preferences", erase: execForUser(`DELETE FROM user_travel_preferences WHERE user_id = $1`)},
+	{name: "user_favorite_hotels", erase: execForUser(`DELETE FROM user_favorite_hotels WHERE user_id = $1`)},
+	{name: "travel_documents", erase: execForUser(`DELETE FROM travel_documents WHERE user_id = $1`)},
+	{name: "user_companions", erase: execForUser(`DELETE FROM user_companions WHERE user_id = $1`)},
+	{name: "phone_verifications", erase: execForUser(`DELETE FROM phone_verifications WHERE user_id = $1`)},
+	{name: "oauth_sessions", erase: execForUser(`DELETE FROM oauth_sessions WHERE user_id = $1`)},
+	{name: "auth_codes", erase: execForUser(`DELETE FROM auth_codes WHERE user_id = $1`)},
+	{name: "data_exports", erase: eraseDataExports},
+	{name: "security_events", erase: execForUser(`UPDATE security_events SET ip_address = NULL, user_agent = NULL WHERE user_id = $1`)},
+	{name: "users", erase: eraseUserRow},
+}
+
+// EraseUser - irreversibly anonymizes the user's PII (GDPR right to erasure).
+// Sessions and identities are deleted, the user can no longer log in and issued
+// tokens stop working because the tombstone is marked deleted.
+// audit carries actor and request info, the entry is written in the same transaction.
+func (s *UserService) EraseUser(id uuid.UUID, audit models.AuditLog) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var purged bool
+	err = tx.QueryRow(ctx, `SELECT purged_at IS NOT NULL FROM users WHERE id = $1 FOR UPDATE`, id).Scan(&purged)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	if purged {
+		return ErrUserAlreadyErased
+	}
+
+	erased, media, err := eraseUserData(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if audit.Metadata == nil {
+		audit.Metadata = map[string]any{}
+	}
+	audit.Metadata["erased"] = erased
+	audit.Action = models.AuditActionUserErased
+	audit.TargetType = models.AuditTargetUser
+	audit.TargetID = &id
+
+	if err := insertAuditLog(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	deleteUserMedia(ctx, s.media, media)
+	return nil
+}
+
+// userMediaFile — a file of an erased user, deleted from media storage after commit
+type userMediaFile struct {
+	userID uuid.UUID
+	url    string
+}
+
+// eraseUserData — runs every eraser for the user in tx, returns the erased names and
+// the media files to delete once tx commits
+func eraseUserData(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]string, []userMediaFile, error) {
+	erased := make([]string, 0, len(userDataErasers))
+	var media []userMediaFile
+	for _, eraser := range userDataErasers {
+		if eraser.media != nil {
+			urls, err := eraser.media(ctx, tx, userID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("erase %s: %w", eraser.name, err)
+			}
+			for _, url := range urls {
+				media = append(media, userMediaFile{userID: userID, url: url})
+			}
+		} else if err := eraser.erase(ctx, tx, userID); err != nil {
+			return nil, nil, fmt.Errorf("erase %s: %w", eraser.name, err)
+		}
+		erased = append(erased, eraser.name)
+	}
+	return erased, media, nil
+}
+
+// userMedia — media files of the user from every media eraser, for removals that skip eraseUserData
+func userMedia(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]userMediaFile, error) {
+	var media []userMediaFile
+	for _, eraser := range userDataErasers {
+		if eraser.media == nil {
+			continue
+		}
+		urls, err := eraser.media(ctx, tx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("erase %s: %w", eraser.name, err)
+		}
+		for _, url := range urls {
+			media = append(media, userMediaFile{userID: userID, url: url})
+		}
+	}
+	return media, nil
+}
+
+// deleteUserMedia — removes the files of erased users, best effort: the rows are already gone,
+// a leftover file is logged and not retried. Without storage (tests, tools) nothing is deleted.
+func deleteUserMedia(ctx context.Context, store storage.Storage, files []userMediaFile) {
+	if store == nil {
+		return
+	}
+	for _, file := range files {
+		deleteAvatarFiles(ctx, store, file.userID, file.url)
+	}
+}
+
+func execForUser(query string) func(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	return func(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+		_, err := tx.Exec(ctx, query, userID)
+		return err
+	}
+}
+
+// userAvatarURLs — the uploaded avatar of the user, its original and thumbnails are deleted after commit
+func userAvatarURLs(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT avatar_url FROM user_profiles WHERE user_id = $1 AND avatar_url IS NOT NULL`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// eraseUserProfile — the profile row is kept, every personal column is cleared
+func eraseUserProfile(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE user_profiles
+		SET phone = NULL, phone_verified_at = NULL, address = NULL, bio = NULL, avatar_url = NULL, updated_at = NOW()
+		WHERE user_id = $1`, userID)
+	return err
+}
+
+func eraseUserRow(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, anonymizeUsersSQL, []uuid.UUID{userID})
+	return err
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// GetIdentities - linked OAuth identities of many users in one query, users without any are absent
+func (s *UserService) GetIdentities(userIDs []uuid.UUID) (map[uuid.UUID][]models.UserIdentity, error) {
+	identities := make(map[uuid.UUID][]models.UserIdentity, len(userIDs))
+	if len(userIDs) == 0 {
+		return identities, nil
+	}
+
+	rows, err := s.db.Query(context.Background(), `
+		SELECT DISTINCT user_id, provider, provider_id
+		FROM oauth_sessions
+		WHERE user_id = ANY($1)
+		ORDER BY user_id, provider, provider_id`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uuid.UUID
+		var identity models.UserIdentity
+		if err := rows.Scan(&userID, &identity.Provider, &identity.ProviderID); err != nil {
+			return nil, err
+		}
+		identities[userID] = append(identities[userID], identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// GetStats - activity counters of many users in one query, every requested user is present
+func (s *UserService) GetStats(userIDs []uuid.UUID) (map[uuid.UUID]models.UserStats, error) {
+	stats := make(map[uuid.UUID]models.UserStats, len(userIDs))
+	if len(userIDs) == 0 {
+		return stats, nil
+	}
+
+	rows, err := s.db.Query(context.Background(), `
+		SELECT u.id,
+			(SELECT COUNT(*) FROM oauth_sessions o WHERE o.user_id = u.id),
+			(SELECT MAX(o.created_at) FROM oauth_sessions o WHERE o.user_id = u.id),
+			(SELECT COUNT(*) FROM security_events e WHERE e.user_id = u.id)
+		FROM users u
+		WHERE u.id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uuid.UUID
+		var loginCount, securityEventCount int64
+		var lastLoginAt *time.Time
+		if err := rows.Scan(&userID, &loginCount, &lastLoginAt, &securityEventCount); err != nil {
+			return nil, err
+		}
+		stats[userID] = models.UserStats{
+			LoginCount:         int(loginCount),
+			LastLoginAt:        lastLoginAt,
+			SecurityEventCount: int(securityEventCount),
+		}
+	}
+
+	return stats, rows.Err()
+}
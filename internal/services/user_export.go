@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+// StreamUsers — calls fn for every user matching the filter, rows are read from the
+// database cursor one by one and never collected; only the columns behind fields are read (all when empty)
+func (s *UserService) StreamUsers(ctx context.Context, filter models.UserFilter, fields []string, fn func(models.User) error) error {
+	columns, err := userColumnsFor(fields, "id")
+	if err != nil {
+		return err
+	}
+
+	args := []any{}
+	conditions := buildUserFilter(filter, &args)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY id
+	`, strings.Join(columns, ", "), strings.Join(conditions, " AND "))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUserColumns(rows, columns)
+		if err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
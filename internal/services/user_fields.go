@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrInvalidField = errors.New("invalid field")
+
+// userFieldColumns — ?fields= names (dto.UserResponse JSON keys) -> column the field is built from,
+// country / city names are looked up by their IDs
+var userFieldColumns = map[string]string{
+	"id":         "id",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"email":      "email",
+	"role":       "role",
+	"birth":      "birth",
+	"gender":     "gender",
+	"country_id": "country_id",
+	"country":    "country_id",
+	"city_id":    "city_id",
+	"city":       "city_id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// ValidateUserFields — every requested field must be in the allow-list
+func ValidateUserFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := userFieldColumns[field]; !ok {
+			return fmt.Errorf("%w: %q", ErrInvalidField, field)
+		}
+	}
+	return nil
+}
+
+// userColumnsFor — columns to select for the fields plus the required ones, in userColumns order;
+// no fields means every column
+func userColumnsFor(fields []string, required ...string) ([]string, error) {
+	if len(fields) == 0 {
+		return userColumns, nil
+	}
+	if err := ValidateUserFields(fields); err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, column := range required {
+		wanted[column] = true
+	}
+	for _, field := range fields {
+		wanted[userFieldColumns[field]] = true
+	}
+
+	columns := make([]string, 0, len(wanted))
+	for _, column := range userColumns {
+		if wanted[column] {
+			columns = append(columns, column)
+		}
+	}
+	return slices.Clip(columns), nil
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+// MaxImportRows — rows accepted by one import, bigger files have to be split
+const MaxImportRows = 10000
+
+var (
+	ErrInvalidImportFormat = errors.New("invalid import format, expected csv or ndjson")
+	ErrImportTooLarge      = fmt.Errorf("import is limited to %d rows", MaxImportRows)
+	ErrInvalidImportHeader = errors.New("invalid csv header")
+)
+
+// importColumns — CSV header / NDJSON keys, the first five are required;
+// status is active or pending, pending accounts cannot sign in until an admin activates them
+var importColumns = []string{"first_name", "last_name", "email", "password", "role", "birth", "gender", "country_id", "city_id", "status"}
+
+// importCopyColumns — users columns written by COPY
+var importCopyColumns = []string{
+	"id", "first_name", "last_name", "email", "password_hash", "role",
+	"birth", "gender", "country_id", "city_id", "status", "created_at", "updated_at",
+}
+
+// importRecord — one input row, raw strings before conversion to models.User
+type importRecord struct {
+	index  int // position in the report
+	line   int
+	values map[string]string // "_error" holds a parse error of the row
+	user   models.User
+	status string
+	hash   string
+}
+
+// UserImportService — bulk creation of users from CSV / NDJSON
+type UserImportService struct {
+	db        db_interface
+	hasher    utils.PasswordHasher
+	validator *validator.Validate
+	newID     func() uuid.UUID // IDs are assigned before COPY, so the report can show them
+}
+
+func NewUserImportService(db db_interface, hasher utils.PasswordHasher) *UserImportService {
+	if hasher == nil {
+		hasher = &utils.BcryptHasher{}
+	}
+	return &UserImportService{db: db, hasher: hasher, validator: validator.New(), newID: uuid.New}
+}
+
+// Import — validates every row with the models.User rules, skips emails that already exist
+// (or repeat in the file) and creates the rest with one COPY; dryRun only builds the report
+func (s *UserImportService) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (models.UserImportReport, error) {
+	var records []*importRecord
+	var err error
+	switch format {
+	case models.ImportFormatCSV:
+		records, err = readImportCSV(r)
+	case models.ImportFormatNDJSON:
+		records, err = readImportNDJSON(r)
+	default:
+		return models.UserImportReport{}, ErrInvalidImportFormat
+	}
+	if err != nil {
+		return models.UserImportReport{}, err
+	}
+
+	report := models.UserImportReport{DryRun: dryRun, Total: len(records), Rows: make([]models.UserImportRow, len(records))}
+	var valid []*importRecord
+	seen := map[string]bool{}
+	for i, record := range records {
+		record.index = i
+		row := &report.Rows[i]
+		row.Line = record.line
+		row.Email = record.values["email"]
+
+		if err := s.toUser(record); err != nil {
+			row.Status, row.Reason = models.ImportRowFailed, err.Error()
+			continue
+		}
+		if seen[record.user.Email] {
+			row.Status, row.Reason = models.ImportRowSkipped, "duplicate email in file"
+			continue
+		}
+		seen[record.user.Email] = true
+		valid = append(valid, record)
+	}
+
+	existing, err := s.existingEmails(ctx, valid)
+	if err != nil {
+		return models.UserImportReport{}, err
+	}
+	toCreate := valid[:0]
+	for _, record := range valid {
+		if existing[record.user.Email] {
+			row := &report.Rows[record.index]
+			row.Status, row.Reason = models.ImportRowSkipped, "email already exists"
+			continue
+		}
+		toCreate = append(toCreate, record)
+	}
+
+	if !dryRun && len(toCreate) > 0 {
+		if err := s.hashPasswords(toCreate); err != nil {
+			return models.UserImportReport{}, err
+		}
+		created, err := s.copyUsers(ctx, toCreate)
+		if err != nil {
+			return models.UserImportReport{}, err
+		}
+		for _, record := range toCreate {
+			row := &report.Rows[record.index]
+			if !created[record.user.ID] {
+				// taken between the check and the insert
+				row.Status, row.Reason = models.ImportRowSkipped, "email already exists"
+				continue
+			}
+			id := record.user.ID
+			row.Status, row.ID = models.ImportRowCreated, &id
+		}
+	} else {
+		for _, record := range toCreate {
+			report.Rows[record.index].Status = models.ImportRowCreated
+		}
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case models.ImportRowCreated:
+			report.Created++
+		case models.ImportRowSkipped:
+			report.Skipped++
+		case models.ImportRowFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// toUser — converts raw values and validates them like POST /api/v1/users does
+func (s *UserImportService) toUser(record *importRecord) error {
+	values := record.values
+	if message := values["_error"]; message != "" {
+		return errors.New(message)
+	}
+	user := models.User{
+		ID:        s.newID(),
+		FirstName: values["first_name"],
+		LastName:  values["last_name"],
+		Email:     strings.TrimSpace(values["email"]),
+		Password:  values["password"],
+		Role:      values["role"],
+	}
+
+	if value := values["birth"]; value != "" {
+		birth, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return errors.New("birth must be YYYY-MM-DD")
+		}
+		user.Birth = &birth
+	}
+	if value := values["gender"]; value != "" {
+		user.Gender = &value
+	}
+	for column, target := range map[string]**uuid.UUID{"country_id": &user.CountryID, "city_id": &user.CityID} {
+		if value := values[column]; value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s", column)
+			}
+			*target = &id
+		}
+	}
+
+	if err := s.validator.Struct(user); err != nil {
+		return err
+	}
+
+	status := values["status"]
+	switch status {
+	case "":
+		status = models.UserStatusActive
+	case models.UserStatusActive, models.UserStatusPending:
+	default:
+		return errors.New("status must be active or pending")
+	}
+
+	record.user, record.status = user, status
+	return nil
+}
+
+func (s *UserImportService) existingEmails(ctx context.Context, records []*importRecord) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(records) == 0 {
+		return existing, nil
+	}
+
+	emails := make([]string, 0, len(records))
+	for _, record := range records {
+		emails = append(emails, record.user.Email)
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT email FROM users WHERE email = ANY($1) AND deleted_at IS NULL`, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		existing[email] = true
+	}
+	return existing, rows.Err()
+}
+
+// hashPasswords — bcrypt is the slow part of an import, rows are hashed in parallel
+func (s *UserImportService) hashPasswords(records []*importRecord) error {
+	jobs := make(chan *importRecord)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range jobs {
+				hash, err := s.hasher.HashPassword(record.user.Password)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				record.hash = hash
+			}
+		}()
+	}
+
+	for _, record := range records {
+		jobs <- record
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// copyUsers — COPY into a temp table, then one INSERT that skips emails taken meanwhile;
+// returns IDs of the created users
+func (s *UserImportService) copyUsers(ctx context.Context, records []*importRecord) (map[uuid.UUID]bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `CREATE TEMP TABLE users_import (LIKE users INCLUDING DEFAULTS) ON COMMIT DROP`); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"users_import"}, importCopyColumns,
+		pgx.CopyFromSlice(len(records), func(i int) ([]any, error) {
+			user := records[i].user
+			return []any{
+				user.ID, user.FirstName, user.LastName, user.Email, records[i].hash, user.Role,
+				user.Birth, user.Gender, user.CountryID, user.CityID, records[i].status, now, now,
+			}, nil
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := strings.Join(importCopyColumns, ", ")
+	rows, err := tx.Query(ctx, `
+		INSERT INTO users (`+columns+`)
+		SELECT `+columns+` FROM users_import
+		ON CONFLICT (email) WHERE deleted_at IS NULL DO NOTHING
+		RETURNING id`)
+	if err != nil {
+		return nil, err
+	}
+	created, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]bool, len(created))
+	for _, id := range created {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// readImportCSV — header row with column names from importColumns, any order
+func readImportCSV(r io.Reader) ([]*importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImportHeader, err.Error())
+	}
+	known := map[string]bool{}
+	for _, column := range importColumns {
+		known[column] = true
+	}
+	present := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !known[column] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImportHeader, column)
+		}
+		header[i] = column
+		present[column] = true
+	}
+	for _, column := range importColumns[:5] {
+		if !present[column] {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImportHeader, column)
+		}
+	}
+
+	var records []*importRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			// a broken row fails alone, reading goes on
+			records = append(records, &importRecord{line: parseErr.Line, values: map[string]string{"_error": parseErr.Err.Error()}})
+		} else {
+			// FieldPos is only valid after a successful Read
+			line, _ := reader.FieldPos(0)
+			values := make(map[string]string, len(header))
+			for i, column := range header {
+				values[column] = strings.TrimSpace(fields[i])
+			}
+			records = append(records, &importRecord{line: line, values: values})
+		}
+		if len(records) > MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+	}
+
+	return records, nil
+}
+
+// readImportNDJSON — one JSON object per line with keys from importColumns, blank lines are ignored
+func readImportNDJSON(r io.Reader) ([]*importRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []*importRecord
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var raw map[string]any
+		values := map[string]string{}
+		if err := json.Unmarshal([]byte(text), &raw); err != nil {
+			values["_error"] = "invalid JSON"
+		} else {
+			for key, value := range raw {
+				if value == nil {
+					continue
+				}
+				values[key] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		records = append(records, &importRecord{line: line, values: values})
+		if len(records) > MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+	}
+
+	return records, scanner.Err()
+}
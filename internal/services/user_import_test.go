@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+type plainHasher struct{}
+
+func (plainHasher) HashPassword(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+// sequentialIDs — предсказуемые ID, чтобы мок мог вернуть их из INSERT ... RETURNING
+func sequentialIDs(ids ...uuid.UUID) func() uuid.UUID {
+	return func() uuid.UUID {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+}
+
+const importCSV = `first_name,last_name,email,password,role
+John,Doe,john@example.com,secret123,user
+Jane,Doe,not-an-email,secret123,user
+Jim,Beam,existing@example.com,secret123,user
+John,Again,john@example.com,secret123,user
+`
+
+func TestImport_CSV(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	john, jane, jim, again := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	service := NewUserImportService(mock, plainHasher{})
+	service.newID = sequentialIDs(john, jane, jim, again)
+
+	mock.ExpectQuery(`SELECT email FROM users WHERE email = ANY\(\$1\)`).
+		WithArgs([]string{"john@example.com", "existing@example.com"}).
+		WillReturnRows(pgxmock.NewRows([]string{"email"}).AddRow("existing@example.com"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TEMP TABLE users_import`).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"users_import"}, importCopyColumns).WillReturnResult(1)
+	mock.ExpectQuery(`INSERT INTO users \(.+\)\s+SELECT .+ FROM users_import\s+ON CONFLICT \(email\) WHERE deleted_at IS NULL DO NOTHING`).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(john))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	report, err := service.Import(context.Background(), strings.NewReader(importCSV), models.ImportFormatCSV, false)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+
+	assert.Equal(t, models.UserImportRow{Line: 2, Email: "john@example.com", Status: models.ImportRowCreated, ID: &john}, report.Rows[0])
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, models.ImportRowFailed, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Reason, "Email")
+	assert.Equal(t, "email already exists", report.Rows[2].Reason)
+	assert.Equal(t, "duplicate email in file", report.Rows[3].Reason)
+}
+
+func TestImport_NDJSONDryRun(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	input := `{"first_name":"John","last_name":"Doe","email":"john@example.com","password":"secret123","role":"user","birth":"1990-05-01"}
+
+{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"secret123","role":"user","birth":"01.05.1990"}
+not json
+`
+	mock.ExpectQuery(`SELECT email FROM users`).
+		WithArgs([]string{"john@example.com"}).
+		WillReturnRows(pgxmock.NewRows([]string{"email"}))
+
+	// dry run: ни хеширования, ни COPY
+	report, err := NewUserImportService(mock, plainHasher{}).
+		Import(context.Background(), strings.NewReader(input), models.ImportFormatNDJSON, true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, "birth must be YYYY-MM-DD", report.Rows[1].Reason)
+	assert.Equal(t, "invalid JSON", report.Rows[2].Reason)
+	assert.Nil(t, report.Rows[0].ID)
+}
+
+func TestImport_PendingStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	input := `first_name,last_name,email,password,role,status
+John,Doe,john@example.com,secret123,user,pending
+Jane,Doe,jane@example.com,secret123,user,
+Jim,Beam,jim@example.com,secret123,user,banned
+`
+	john, jane, jim := uuid.New(), uuid.New(), uuid.New()
+	service := NewUserImportService(mock, plainHasher{})
+	service.newID = sequentialIDs(john, jane, jim)
+
+	mock.ExpectQuery(`SELECT email FROM users`).
+		WithArgs([]string{"john@example.com", "jane@example.com"}).
+		WillReturnRows(pgxmock.NewRows([]string{"email"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TEMP TABLE users_import`).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"users_import"}, importCopyColumns).WillReturnResult(2)
+	mock.ExpectQuery(`INSERT INTO users`).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(john).AddRow(jane))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	// pending-аккаунт не может войти, пока админ не активирует его
+	report, err := service.Import(context.Background(), strings.NewReader(input), models.ImportFormatCSV, false)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, "status must be active or pending", report.Rows[2].Reason)
+}
+
+func TestImport_CSVHeader(t *testing.T) {
+	service := NewUserImportService(nil, plainHasher{})
+
+	_, err := service.Import(context.Background(), strings.NewReader("email,password\n"), models.ImportFormatCSV, true)
+	assert.ErrorIs(t, err, ErrInvalidImportHeader)
+
+	_, err = service.Import(context.Background(), strings.NewReader("first_name,last_name,email,password,role,is_admin\n"), models.ImportFormatCSV, true)
+	assert.ErrorIs(t, err, ErrInvalidImportHeader)
+
+	_, err = service.Import(context.Background(), strings.NewReader(""), "xml", true)
+	assert.ErrorIs(t, err, ErrInvalidImportFormat)
+}
+
+func TestImport_CSVBrokenRow(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	input := `first_name,last_name,email,password,role
+B"ad,Quote,bad@example.com,secret123,user
+John,Doe,john@example.com,secret123,user
+`
+	mock.ExpectQuery(`SELECT email FROM users`).
+		WithArgs([]string{"john@example.com"}).
+		WillReturnRows(pgxmock.NewRows([]string{"email"}))
+
+	// битая строка попадает в отчёт, а не роняет запрос
+	report, err := NewUserImportService(mock, plainHasher{}).
+		Import(context.Background(), strings.NewReader(input), models.ImportFormatCSV, true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Rows[0].Line)
+	assert.Equal(t, models.ImportRowFailed, report.Rows[0].Status)
+	assert.Contains(t, report.Rows[0].Reason, "quote")
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, models.ImportRowCreated, report.Rows[1].Status)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+const (
+	DefaultUsersPageLimit = 20
+	MaxUsersPageLimit     = 100
+	defaultUsersSort      = "-created_at"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// userSelectColumns — columns read by scanUser, keep them in sync with userColumns
+const userSelectColumns = `
+			id,
+			first_name,
+			last_name,
+			email,
+			role,
+			birth,
+			gender,
+			country_id,
+			city_id,
+			created_at,
+			updated_at,
+			deleted_at,
+			version`
+
+// userColumns — userSelectColumns as a list, the order sparse fieldsets select in
+var userColumns = []string{
+	"id", "first_name", "last_name", "email", "role", "birth", "gender",
+	"country_id", "city_id", "created_at", "updated_at", "deleted_at", "version",
+}
+
+// sortField — column allowed in ?sort= and how its cursor value is typed
+type sortField struct {
+	expr   string // SQL expression, nullable columns are coalesced for keyset comparison
+	isTime bool
+}
+
+var userSortFields = map[string]sortField{
+	"created_at": {expr: "created_at", isTime: true},
+	"first_name": {expr: "COALESCE(first_name, '')"},
+	"last_name":  {expr: "COALESCE(last_name, '')"},
+	"email":      {expr: "email"},
+}
+
+// userCursor — decoded content of the opaque cursor
+type userCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// ListUsers — keyset-paginated, filtered and sorted listing of users (non-deleted unless the filter says otherwise)
+func (s *UserService) ListUsers(params models.UserListParams) (models.UserPage, error) {
+	if params.Limit <= 0 {
+		params.Limit = DefaultUsersPageLimit
+	}
+	if params.Limit > MaxUsersPageLimit {
+		params.Limit = MaxUsersPageLimit
+	}
+	if params.Sort == "" {
+		params.Sort = defaultUsersSort
+	}
+
+	field, desc, err := parseUserSort(params.Sort)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	// the sort column is always read, the next cursor is built from it
+	columns, err := userColumnsFor(params.Fields, "id", strings.TrimPrefix(params.Sort, "-"))
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	args := []any{}
+	conditions := buildUserFilter(params.Filter, &args)
+
+	if params.Cursor != "" {
+		cursor, err := decodeUserCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return models.UserPage{}, err
+		}
+
+		var value any = cursor.Value
+		if field.isTime {
+			t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return models.UserPage{}, ErrInvalidCursor
+			}
+			value = t
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		args = append(args, value, cursor.ID)
+		conditions = append(conditions,
+			fmt.Sprintf("(%s, id) %s ($%d, $%d)", field.expr, op, len(args)-1, len(args)))
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	// limit + 1 row tells whether there is a next page
+	args = append(args, params.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d
+	`, strings.Join(columns, ", "), strings.Join(conditions, " AND "), field.expr, direction, direction, len(args))
+
+	rows, err := s.db.Query(context.Background(), query, args...)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, params.Limit)
+	for rows.Next() {
+		user, err := scanUserColumns(rows, columns)
+		if err != nil {
+			return models.UserPage{}, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return models.UserPage{}, err
+	}
+
+	page := models.UserPage{Users: users}
+	if len(users) > params.Limit {
+		page.Users = users[:params.Limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = encodeUserCursor(params.Sort, last)
+	}
+
+	return page, nil
+}
+
+// buildUserFilter — WHERE conditions for the filter, appends placeholders values to args
+func buildUserFilter(filter models.UserFilter, args *[]any) []string {
+	var conditions []string
+
+	switch {
+	case filter.OnlyDeleted:
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	case !filter.IncludeDeleted:
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	add := func(condition string, value any) {
+		*args = append(*args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(*args)))
+	}
+
+	if filter.Role != nil {
+		add("role = $%d", *filter.Role)
+	}
+	if filter.Gender != nil {
+		add("gender = $%d", *filter.Gender)
+	}
+	if filter.CountryID != nil {
+		add("country_id = $%d", *filter.CountryID)
+	}
+	if filter.CityID != nil {
+		add("city_id = $%d", *filter.CityID)
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at < $%d", *filter.CreatedTo)
+	}
+
+	// age N or more -> born at least N years ago
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if filter.AgeMin != nil {
+		add("birth <= $%d", today.AddDate(-*filter.AgeMin, 0, 0))
+	}
+	// age N or less -> born less than N+1 years ago
+	if filter.AgeMax != nil {
+		add("birth > $%d", today.AddDate(-(*filter.AgeMax + 1), 0, 0))
+	}
+
+	return conditions
+}
+
+// parseUserSort — "-created_at" -> (created_at field, desc)
+func parseUserSort(sort string) (sortField, bool, error) {
+	desc := strings.HasPrefix(sort, "-")
+	field, ok := userSortFields[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return sortField{}, false, ErrInvalidSort
+	}
+	return field, desc, nil
+}
+
+func encodeUserCursor(sort string, last models.User) string {
+	cursor := userCursor{Sort: sort, ID: last.ID}
+
+	switch strings.TrimPrefix(sort, "-") {
+	case "created_at":
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	case "first_name":
+		cursor.Value = last.FirstName
+	case "last_name":
+		cursor.Value = last.LastName
+	case "email":
+		cursor.Value = last.Email
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeUserCursor — cursor is only valid for the sort it was produced with
+func decodeUserCursor(encoded, sort string) (userCursor, error) {
+	var cursor userCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort || cursor.ID == uuid.Nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// scanUser — scans a row selected with userSelectColumns, extra targets follow them
+func scanUser(row pgx.Row, extra ...any) (models.User, error) {
+	return scanUserColumns(row, userColumns, extra...)
+}
+
+// scanUserColumns — scans a row selected with the given subset of userColumns (in that order),
+// fields of columns not selected stay zero
+func scanUserColumns(row pgx.Row, columns []string, extra ...any) (models.User, error) {
+	var user models.User
+	var firstName, lastName, gender, countryID, cityID sql.NullString
+
+	targets := map[string]any{
+		"id":         &user.ID,
+		"first_name": &firstName,
+		"last_name":  &lastName,
+		"email":      &user.Email,
+		"role":       &user.Role,
+		"birth":      &user.Birth,
+		"gender":     &gender,
+		"country_id": &countryID,
+		"city_id":    &cityID,
+		"created_at": &user.CreatedAt,
+		"updated_at": &user.UpdatedAt,
+		"deleted_at": &user.DeletedAt,
+		"version":    &user.Version,
+	}
+
+	dest := make([]any, 0, len(columns)+len(extra))
+	for _, column := range columns {
+		dest = append(dest, targets[column])
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.User{}, err
+	}
+
+	user.FirstName = firstName.String
+	user.LastName = lastName.String
+
+	if gender.Valid {
+		user.Gender = &gender.String
+	}
+	if countryID.Valid {
+		id, _ := uuid.Parse(countryID.String)
+		user.CountryID = &id
+	}
+	if cityID.Valid {
+		id, _ := uuid.Parse(cityID.String)
+		user.CityID = &id
+	}
+
+	return user, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+var (
+	ErrEmailTaken        = errors.New("email is already taken")
+	ErrFieldNotPatchable = errors.New("field cannot be patched")
+)
+
+// patchableUserColumns — columns PatchUser is allowed to write
+var patchableUserColumns = map[string]bool{
+	"first_name": true,
+	"last_name":  true,
+	"email":      true,
+	"birth":      true,
+	"gender":     true,
+	"country_id": true,
+	"city_id":    true,
+}
+
+// PatchUser - updates only the given columns (nil value writes NULL) and returns the fresh row
+func (s *UserService) PatchUser(id uuid.UUID, changes map[string]any, ifMatch []int) (models.User, error) {
+	if len(changes) == 0 {
+		user, err := s.GetUser(id)
+		if err == nil && ifMatch != nil && !containsVersion(ifMatch, user.Version) {
+			return models.User{}, ErrPreconditionFailed
+		}
+		return user, err
+	}
+
+	// stable column order keeps the generated SQL predictable
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		if !patchableUserColumns[column] {
+			return models.User{}, fmt.Errorf("%w: %s", ErrFieldNotPatchable, column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := make([]any, 0, len(columns)+1)
+	assignments := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		args = append(args, changes[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	assignments = append(assignments, "updated_at = NOW()")
+
+	args = append(args, id, ifMatch)
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET %s
+		WHERE id = $%d AND deleted_at IS NULL AND ($%d::int[] IS NULL OR version = ANY($%d))
+		RETURNING`+userSelectColumns,
+		strings.Join(assignments, ", "), len(args)-1, len(args), len(args))
+
+	user, err := scanUser(s.db.QueryRow(context.Background(), query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, s.notFoundOrPreconditionFailed(id, ifMatch)
+		}
+		if isUniqueViolation(err) {
+			return models.User{}, ErrEmailTaken
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// isUniqueViolation — PostgreSQL unique_violation (23505)
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func containsVersion(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/utils"
+)
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrPhoneTaken      = errors.New("phone is already taken")
+	ErrInvalidPhone    = errors.New("invalid phone")
+)
+
+const profileSelectColumns = `id, user_id, phone, phone_verified_at, address, bio, avatar_url, created_at, updated_at`
+
+// profileColumns — columns written through the profile API
+var profileColumns = map[string]bool{
+	"phone":      true,
+	"address":    true,
+	"bio":        true,
+	"avatar_url": true,
+}
+
+func scanProfile(row pgx.Row) (models.UserProfile, error) {
+	var profile models.UserProfile
+	err := row.Scan(&profile.ID, &profile.UserID, &profile.Phone, &profile.PhoneVerifiedAt, &profile.Address,
+		&profile.Bio, &profile.AvatarURL, &profile.CreatedAt, &profile.UpdatedAt)
+	return profile, err
+}
+
+// GetProfile - profile of an active user
+func (s *UserService) GetProfile(userID uuid.UUID) (models.UserProfile, error) {
+	query := `
+		SELECT ` + profileSelectColumns + `
+		FROM user_profiles
+		WHERE user_id = $1
+		AND EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`
+
+	profile, err := scanProfile(s.db.QueryRow(context.Background(), query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserProfile{}, ErrProfileNotFound
+		}
+		return models.UserProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// GetProfiles - profiles of many users in one query (for ?expand=profile), users without a profile are absent
+func (s *UserService) GetProfiles(userIDs []uuid.UUID) (map[uuid.UUID]models.UserProfile, error) {
+	profiles := make(map[uuid.UUID]models.UserProfile, len(userIDs))
+	if len(userIDs) == 0 {
+		return profiles, nil
+	}
+
+	rows, err := s.db.Query(context.Background(),
+		`SELECT `+profileSelectColumns+` FROM user_profiles WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles[profile.UserID] = profile
+	}
+
+	return profiles, rows.Err()
+}
+
+// ReplaceProfile - PUT semantics: every profile field is written, absent ones become NULL
+func (s *UserService) ReplaceProfile(userID uuid.UUID, profile models.UserProfile) (models.UserProfile, error) {
+	return s.PatchProfile(userID, map[string]any{
+		"phone":      nullableString(profile.Phone),
+		"address":    nullableString(profile.Address),
+		"bio":        nullableString(profile.Bio),
+		"avatar_url": nullableString(profile.AvatarURL),
+	})
+}
+
+// nullableString — nil pointer -> untyped nil (NULL), otherwise the plain string
+func nullableString(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// PatchProfile - writes only the given columns (nil value writes NULL), creates the profile if the user has none.
+// Phone is normalized to E.164, changing it drops the verification.
+func (s *UserService) PatchProfile(userID uuid.UUID, changes map[string]any) (models.UserProfile, error) {
+	if phone, ok := changes["phone"].(string); ok {
+		normalized, err := s.normalizePhone(userID, phone)
+		if err != nil {
+			return models.UserProfile{}, err
+		}
+		changes["phone"] = normalized
+	}
+
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		if !profileColumns[column] {
+			return models.UserProfile{}, fmt.Errorf("%w: %s", ErrFieldNotPatchable, column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := []any{userID}
+	values := make([]string, 0, len(columns))
+	assignments := []string{"updated_at = NOW()"}
+	for _, column := range columns {
+		args = append(args, changes[column])
+		values = append(values, fmt.Sprintf("$%d", len(args)))
+		assignments = append(assignments, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+	}
+	if _, ok := changes["phone"]; ok {
+		assignments = append(assignments, `phone_verified_at = CASE
+			WHEN user_profiles.phone IS DISTINCT FROM EXCLUDED.phone THEN NULL
+			ELSE user_profiles.phone_verified_at END`)
+	}
+
+	insertColumns := append([]string{"user_id"}, columns...)
+	selectValues := append([]string{"id"}, values...)
+
+	// SELECT from users: deleted users cannot get a profile, no row means not found
+	query := fmt.Sprintf(`
+		INSERT INTO user_profiles (%s)
+		SELECT %s FROM users WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (user_id) DO UPDATE SET %s
+		RETURNING `+profileSelectColumns,
+		strings.Join(insertColumns, ", "), strings.Join(selectValues, ", "), strings.Join(assignments, ", "))
+
+	profile, err := scanProfile(s.db.QueryRow(context.Background(), query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserProfile{}, errors.New("user not found")
+		}
+		if isUniqueViolation(err) {
+			return models.UserProfile{}, ErrPhoneTaken
+		}
+		return models.UserProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// normalizePhone — E.164 with the user's country as the region for national numbers
+func (s *UserService) normalizePhone(userID uuid.UUID, phone string) (string, error) {
+	region, err := s.userRegion(userID)
+	if err != nil {
+		return "", err
+	}
+
+	normalized, err := utils.NormalizePhone(phone, region)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPhone, err.Error())
+	}
+	return normalized, nil
+}
+
+// userRegion — ISO alpha-2 code of the user's country, "" when unknown
+func (s *UserService) userRegion(userID uuid.UUID) (string, error) {
+	var countryID *uuid.UUID
+	err := s.db.QueryRow(context.Background(),
+		`SELECT country_id FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(&countryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.New("user not found")
+		}
+		return "", err
+	}
+	if countryID == nil || s.hotelClient == nil {
+		return "", nil
+	}
+
+	countries, err := s.hotelClient.GetLocations()
+	if err != nil {
+		// international numbers still work, national ones get ErrPhoneRegionRequired
+		logrus.WithError(err).Warn("failed to load locations for phone normalization")
+		return "", nil
+	}
+	for _, country := range countries {
+		if country.ID == countryID.String() {
+			return country.Code, nil
+		}
+	}
+
+	return "", nil
+}
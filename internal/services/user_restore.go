@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+var ErrUserNotDeleted = errors.New("user is not deleted")
+
+// RestoreUser - undoes a soft delete. Fails with ErrEmailTaken when an active
+// account registered the same email while this one was deleted.
+// Users anonymized by the purge job are gone for good and reported as not found.
+func (s *UserService) RestoreUser(id uuid.UUID) (models.User, error) {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL AND purged_at IS NULL
+		RETURNING` + userSelectColumns
+
+	user, err := scanUser(s.db.QueryRow(context.Background(), query, id))
+	if err == nil {
+		return user, nil
+	}
+
+	if isUniqueViolation(err) {
+		return models.User{}, ErrEmailTaken
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	var exists bool
+	if err := s.db.QueryRow(context.Background(),
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND purged_at IS NULL)`, id).Scan(&exists); err != nil {
+		return models.User{}, err
+	}
+	if exists {
+		return models.User{}, ErrUserNotDeleted
+	}
+
+	return models.User{}, errors.New("user not found")
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+const (
+	MinSearchQueryLength = 2
+	DefaultSearchLimit   = 20
+	MaxSearchLimit       = 100
+)
+
+var ErrSearchQueryTooShort = errors.New("search query is too short")
+
+// searchDocument — must stay identical to the idx_users_search_tsv index expression (V13)
+const searchDocument = `to_tsvector('simple', COALESCE(first_name, '') || ' ' || COALESCE(last_name, '') || ' ' || email)`
+
+// SearchUsers — fuzzy (pg_trgm) + full-text search over first_name, last_name and email,
+// ranked by the best similarity plus full-text rank
+func (s *UserService) SearchUsers(q string, limit int) ([]models.UserSearchResult, error) {
+	q = strings.TrimSpace(q)
+	if len([]rune(q)) < MinSearchQueryLength {
+		return nil, ErrSearchQueryTooShort
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	query := `
+		SELECT` + userSelectColumns + `,
+			score
+		FROM (
+			SELECT *,
+				GREATEST(
+					similarity(COALESCE(first_name, ''), $1),
+					similarity(COALESCE(last_name, ''), $1),
+					similarity(COALESCE(first_name, '') || ' ' || COALESCE(last_name, ''), $1),
+					similarity(email, $1)
+				) + ts_rank(` + searchDocument + `, plainto_tsquery('simple', $1)) AS score
+			FROM users
+			WHERE deleted_at IS NULL
+			  AND (
+				first_name % $1 OR last_name % $1 OR email % $1
+				OR first_name ILIKE $2 OR last_name ILIKE $2 OR email ILIKE $2
+				OR ` + searchDocument + ` @@ plainto_tsquery('simple', $1)
+			  )
+		) AS matched
+		ORDER BY score DESC, id
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, q, "%"+escapeLike(q)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]models.UserSearchResult, 0)
+	for rows.Next() {
+		var score float64
+
+		user, err := scanUser(rows, &score)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, models.UserSearchResult{User: user, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// escapeLike — user input must not act as LIKE wildcards
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
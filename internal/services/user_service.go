@@ -2,8 +2,8 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"strings"
 
 	//"time"
 
@@ -17,14 +17,23 @@ import (
 	"github.com/vitalii-q/selena-users-service/internal/helpers"
 	"github.com/vitalii-q/selena-users-service/internal/models"
 	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
 	"github.com/vitalii-q/selena-users-service/internal/utils"
 )
 
+var (
+	// ErrInvalidPassword - current password does not match
+	ErrInvalidPassword = errors.New("invalid current password")
+	// ErrPreconditionFailed - If-Match version does not match the stored one
+	ErrPreconditionFailed = errors.New("precondition failed: user was modified")
+)
+
 // UserServiceImpl - implementation of the user service
 type UserService struct {
 	db db_interface
 	passwordHasher utils.PasswordHasher
 	hotelClient *external_services.HotelServiceClient
+	media storage.Storage // avatars of erased users are deleted from it
 }
 
 // NewUserServiceImpl - конструктор UserServiceImpl
@@ -32,11 +41,13 @@ func NewUserService(
 	db db_interface, 
 	passwordHasher utils.PasswordHasher, 
 	hotelClient *external_services.HotelServiceClient,
+	media storage.Storage,
 ) *UserService {
 	return &UserService{
 		db: db, 
 		passwordHasher: passwordHasher,
 		hotelClient: hotelClient,
+		media: media,
 	}
 }
 
@@ -69,42 +80,24 @@ func (s *UserService) CreateUser(user models.User) (models.User, error) {
 
 // GetUser - getting a user by UUID
 func (s *UserService) GetUser(id uuid.UUID) (models.User, error) {
-	var user models.User
-	var gender, countryID, cityID sql.NullString
+	return s.GetUserFields(id, nil)
+}
+
+// GetUserFields - GetUser reading only the columns behind the given response fields (all when empty),
+// id and version are always read
+func (s *UserService) GetUserFields(id uuid.UUID, fields []string) (models.User, error) {
+	columns, err := userColumnsFor(fields, "id", "version")
+	if err != nil {
+		return models.User{}, err
+	}
 
 	query := `
-		SELECT
-			id,
-			first_name,
-			last_name,
-			email,
-			role,
-			birth,
-			gender,
-			country_id,
-			city_id,
-			created_at,
-			updated_at,
-			deleted_at
+		SELECT ` + strings.Join(columns, ", ") + `
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	err := s.db.QueryRow(context.Background(), query, id).Scan(
-		&user.ID,
-		&user.FirstName,
-		&user.LastName,
-		&user.Email,
-		&user.Role,
-		&user.Birth,
-		&gender,
-		&countryID,
-		&cityID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
-
+	user, err := scanUserColumns(s.db.QueryRow(context.Background(), query, id), columns)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.User{}, errors.New("user not found")
@@ -112,29 +105,16 @@ func (s *UserService) GetUser(id uuid.UUID) (models.User, error) {
 		return models.User{}, err
 	}
 
-	// Validation
-	if gender.Valid {
-		user.Gender = &gender.String
-	}
-	if countryID.Valid {
-		id, _ := uuid.Parse(countryID.String)
-		user.CountryID = &id
-	}
-	if cityID.Valid {
-		id, _ := uuid.Parse(cityID.String)
-		user.CityID = &id
-	}
-
 	return user, nil
 }
 
 // GetUserByEmail - receiving a user by email
 func (s *UserService) GetUserByEmail(email string) (models.UserAuth, error) {
 	var user models.UserAuth
-	query := `SELECT id, email, password_hash FROM users WHERE email = $1 AND deleted_at IS NULL`
+	query := `SELECT id, email, role, password_hash FROM users WHERE email = $1 AND deleted_at IS NULL`
 
 	err := s.db.QueryRow(context.Background(), query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash,
+		&user.ID, &user.Email, &user.Role, &user.PasswordHash,
 	)
 
 	if err != nil {
@@ -147,19 +127,20 @@ func (s *UserService) GetUserByEmail(email string) (models.UserAuth, error) {
 	return user, nil
 }
 
-// UpdateUser - updating user data
-func (s *UserService) UpdateUser(id uuid.UUID, updatedUser models.User) (models.User, error) {
+// UpdateUser - updating user data; ifMatch (versions from If-Match, nil = unconditional) guards lost updates
+func (s *UserService) UpdateUser(id uuid.UUID, updatedUser models.User, ifMatch []int) (models.User, error) {
 	query := `UPDATE users 
 			  SET first_name = $1, last_name = $2, email = $3, updated_at = NOW()
-			  WHERE id = $4 RETURNING updated_at`
+			  WHERE id = $4 AND deleted_at IS NULL AND ($5::int[] IS NULL OR version = ANY($5))
+			  RETURNING updated_at, version`
 
 	err := s.db.QueryRow(context.Background(), query,
-		updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, id).
-		Scan(&updatedUser.UpdatedAt)
+		updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, id, ifMatch).
+		Scan(&updatedUser.UpdatedAt, &updatedUser.Version)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.User{}, errors.New("user not found")
+			return models.User{}, s.notFoundOrPreconditionFailed(id, ifMatch)
 		}
 		return models.User{}, err
 	}
@@ -168,37 +149,71 @@ func (s *UserService) UpdateUser(id uuid.UUID, updatedUser models.User) (models.
 	return updatedUser, nil
 }
 
+// ChangePassword - replaces the password after checking the current one
+func (s *UserService) ChangePassword(id uuid.UUID, currentPassword, newPassword string) error {
+	var passwordHash string
+
+	query := `SELECT password_hash FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := s.db.QueryRow(context.Background(), query, id).Scan(&passwordHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	if !utils.CheckPassword(currentPassword, passwordHash) {
+		return ErrInvalidPassword
+	}
+
+	newHash, err := s.passwordHasher.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(context.Background(),
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, newHash, id)
+
+	return err
+}
+
 // DeleteUser - deleting a user by ID
-func (s *UserService) DeleteUser(id uuid.UUID) error {
-	query := `UPDATE users SET deleted_at = NOW() WHERE id = $1`
-	result, err := s.db.Exec(context.Background(), query, id)
+func (s *UserService) DeleteUser(id uuid.UUID, ifMatch []int) error {
+	query := `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL AND ($2::int[] IS NULL OR version = ANY($2))`
+	result, err := s.db.Exec(context.Background(), query, id, ifMatch)
 	if err != nil {
 		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("user not found") 
+		return s.notFoundOrPreconditionFailed(id, ifMatch)
 	}
 
 	return nil
 }
 
+// notFoundOrPreconditionFailed - explains why a conditional write touched no rows
+func (s *UserService) notFoundOrPreconditionFailed(id uuid.UUID, ifMatch []int) error {
+	if ifMatch == nil {
+		return errors.New("user not found")
+	}
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`
+	if err := s.db.QueryRow(context.Background(), query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrPreconditionFailed
+	}
+
+	return errors.New("user not found")
+}
+
 // GetAllUsers — returns all users
 func (s *UserService) GetAllUsers() ([]models.User, error) {
 	query := `
-		SELECT
-			id,
-			first_name,
-			last_name,
-			email,
-			role,
-			birth,
-			gender,
-			country_id,
-			city_id,
-			created_at,
-			updated_at,
-			deleted_at
+		SELECT` + userSelectColumns + `
 		FROM users
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -215,40 +230,11 @@ func (s *UserService) GetAllUsers() ([]models.User, error) {
 	users := make([]models.User, 0)
 
 	for rows.Next() {
-		var user models.User
-		var gender, countryID, cityID sql.NullString
-
-		err := rows.Scan(
-			&user.ID,
-			&user.FirstName,
-			&user.LastName,
-			&user.Email,
-			&user.Role,
-			&user.Birth,
-			&gender,
-			&countryID,
-			&cityID,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-			&user.DeletedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		// Validation
-		if gender.Valid {
-			user.Gender = &gender.String
-		}
-		if countryID.Valid {
-			id, _ := uuid.Parse(countryID.String)
-			user.CountryID = &id
-		}
-		if cityID.Valid {
-			id, _ := uuid.Parse(cityID.String)
-			user.CityID = &id
-		}
-
 		users = append(users, user)
 	}
 
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"github.com/vitalii-q/selena-users-service/internal/models"
 	"github.com/vitalii-q/selena-users-service/internal/services/external_services"
@@ -11,9 +13,27 @@ import (
 type UserServiceInterface interface {
 	CreateUser(user models.User) (models.User, error)
 	GetUser(id uuid.UUID) (models.User, error)
-	UpdateUser(id uuid.UUID, updatedUser models.User) (models.User, error)
-	DeleteUser(id uuid.UUID) error
+	GetUserFields(id uuid.UUID, fields []string) (models.User, error)
+	GetUsersByIDs(ids []uuid.UUID, fields []string) ([]models.User, error)
+	UpdateUser(id uuid.UUID, updatedUser models.User, ifMatch []int) (models.User, error)
+	PatchUser(id uuid.UUID, changes map[string]any, ifMatch []int) (models.User, error)
+	DeleteUser(id uuid.UUID, ifMatch []int) error
+	RestoreUser(id uuid.UUID) (models.User, error)
+	EraseUser(id uuid.UUID, audit models.AuditLog) error
+	BulkUpdateUsers(ctx context.Context, selector models.BulkUserSelector, changes models.BulkUserChanges, audit models.AuditLog) (models.BulkUserReport, error)
+	BulkDeleteUsers(ctx context.Context, selector models.BulkUserSelector, audit models.AuditLog) (models.BulkUserReport, error)
 	GetAllUsers() ([]models.User, error)
+	ListUsers(params models.UserListParams) (models.UserPage, error)
+	SearchUsers(q string, limit int) ([]models.UserSearchResult, error)
+	StreamUsers(ctx context.Context, filter models.UserFilter, fields []string, fn func(models.User) error) error
+
+	GetProfile(userID uuid.UUID) (models.UserProfile, error)
+	GetProfiles(userIDs []uuid.UUID) (map[uuid.UUID]models.UserProfile, error)
+	ReplaceProfile(userID uuid.UUID, profile models.UserProfile) (models.UserProfile, error)
+	PatchProfile(userID uuid.UUID, changes map[string]any) (models.UserProfile, error)
+
+	GetIdentities(userIDs []uuid.UUID) (map[uuid.UUID][]models.UserIdentity, error)
+	GetStats(userIDs []uuid.UUID) (map[uuid.UUID]models.UserStats, error)
 
 	HotelClient() *external_services.HotelServiceClient
 }
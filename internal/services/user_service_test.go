@@ -1,18 +1,22 @@
 package services
 
 import (
-	//"context"
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	//"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pashagolub/pgxmock/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/vitalii-q/selena-users-service/internal/models"
+	"github.com/vitalii-q/selena-users-service/internal/services/storage"
 )
 
 // Мок для PasswordHasher
@@ -136,13 +140,13 @@ func TestUpdateUser(t *testing.T) {
 	}
 	updatedAt := time.Now()
 
-	mock.ExpectQuery(`UPDATE users SET first_name = \$1, last_name = \$2, email = \$3, updated_at = NOW\(\) WHERE id = \$4 RETURNING updated_at`).
-		WithArgs(updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, userID).
-		WillReturnRows(pgxmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+	mock.ExpectQuery(`UPDATE users SET first_name = \$1, last_name = \$2, email = \$3, updated_at = NOW\(\) WHERE id = \$4 AND deleted_at IS NULL AND \(\$5::int\[\] IS NULL OR version = ANY\(\$5\)\) RETURNING updated_at, version`).
+		WithArgs(updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, userID, []int(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"updated_at", "version"}).AddRow(updatedAt, 2))
 
 	userService := NewUserServiceInterface(mock, nil)
 
-	result, err := userService.UpdateUser(userID, updatedUser)
+	result, err := userService.UpdateUser(userID, updatedUser, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, userID, result.ID)
@@ -162,13 +166,13 @@ func TestUpdateUser_NotFound(t *testing.T) {
 		Email:     "new_email@example.com",
 	}
 
-	mock.ExpectQuery(`UPDATE users SET first_name = \$1, last_name = \$2, email = \$3, updated_at = NOW\(\) WHERE id = \$4 RETURNING updated_at`).
-		WithArgs(updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, userID).
+	mock.ExpectQuery(`UPDATE users SET first_name = \$1, last_name = \$2, email = \$3, updated_at = NOW\(\) WHERE id = \$4`).
+		WithArgs(updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, userID, []int(nil)).
 		WillReturnError(pgx.ErrNoRows)
 
 	userService := NewUserServiceInterface(mock, nil)
 
-	result, err := userService.UpdateUser(userID, updatedUser)
+	result, err := userService.UpdateUser(userID, updatedUser, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "user not found", err.Error())
@@ -184,12 +188,12 @@ func TestDeleteUser(t *testing.T) {
 	userID := uuid.New()
 
 	mock.ExpectExec(`UPDATE users SET deleted_at = NOW\(\) WHERE id = \$1`).
-		WithArgs(userID).
+		WithArgs(userID, []int(nil)).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	userService := NewUserServiceInterface(mock, nil)
 
-	err = userService.DeleteUser(userID)
+	err = userService.DeleteUser(userID, nil)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -203,14 +207,465 @@ func TestDeleteUser_Error(t *testing.T) {
 	userID := uuid.New()
 
 	mock.ExpectExec(`UPDATE users SET deleted_at = NOW\(\) WHERE id = \$1`).
-		WithArgs(userID).
+		WithArgs(userID, []int(nil)).
 		WillReturnError(errors.New("database error"))
 
 	userService := NewUserServiceInterface(mock, nil)
 
-	err = userService.DeleteUser(userID)
+	err = userService.DeleteUser(userID, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func userRows() *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"id", "first_name", "last_name", "email", "role", "birth", "gender", "country_id", "city_id", "created_at", "updated_at", "deleted_at", "version"})
+}
+
+func TestListUsers_FirstPageWithFilters(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	role := "user"
+	countryID := uuid.New()
+	createdAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+
+	// limit 1 -> запрашиваем 2 строки, вторая означает наличие следующей страницы
+	mock.ExpectQuery(`FROM users\s+WHERE deleted_at IS NULL AND role = \$1 AND country_id = \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3`).
+		WithArgs(role, countryID, 2).
+		WillReturnRows(userRows().
+			AddRow(first, "John", "Doe", "john@example.com", "user", nil, nil, countryID.String(), nil, createdAt, createdAt, nil, 1).
+			AddRow(second, "Jane", "Doe", "jane@example.com", "user", nil, nil, countryID.String(), nil, createdAt, createdAt, nil, 1))
+
+	userService := NewUserServiceInterface(mock, nil)
+
+	page, err := userService.ListUsers(models.UserListParams{
+		Filter: models.UserFilter{Role: &role, CountryID: &countryID},
+		Limit:  1,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, first, page.Users[0].ID)
+	assert.Equal(t, countryID, *page.Users[0].CountryID)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// курсор первой страницы превращается в keyset-условие
+	cursor, err := decodeUserCursor(page.NextCursor, "-created_at")
+	assert.NoError(t, err)
+	assert.Equal(t, first, cursor.ID)
+
+	mock.ExpectQuery(`WHERE deleted_at IS NULL AND \(created_at, id\) < \(\$1, \$2\)\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3`).
+		WithArgs(createdAt, first, 2).
+		WillReturnRows(userRows().
+			AddRow(second, "Jane", "Doe", "jane@example.com", "user", nil, nil, nil, nil, createdAt, createdAt, nil, 1))
+
+	page, err = userService.ListUsers(models.UserListParams{Limit: 1, Cursor: page.NextCursor})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers_CursorFromAnotherSort(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	cursor := encodeUserCursor("email", models.User{ID: uuid.New(), Email: "a@example.com"})
+
+	_, err = NewUserServiceInterface(mock, nil).ListUsers(models.UserListParams{Sort: "-created_at", Cursor: cursor})
+
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestListUsers_InvalidSort(t *testing.T) {
+	_, err := NewUserServiceInterface(nil, nil).ListUsers(models.UserListParams{Sort: "password_hash"})
+
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestSearchUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+
+	// спецсимволы LIKE экранируются
+	mock.ExpectQuery(`similarity\(email, \$1\).*ILIKE \$2.*ORDER BY score DESC, id\s+LIMIT \$3`).
+		WithArgs("jo_n", `%jo\_n%`, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "first_name", "last_name", "email", "role", "birth", "gender", "country_id", "city_id", "created_at", "updated_at", "deleted_at", "version", "score"}).
+			AddRow(userID, "John", "Doe", "john@example.com", "user", nil, nil, nil, nil, now, now, nil, 1, 0.75))
+
+	results, err := NewUserServiceInterface(mock, nil).SearchUsers("  jo_n ", 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, userID, results[0].ID)
+	assert.Equal(t, 0.75, results[0].Score)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE users SET city_id = \$1, first_name = \$2, updated_at = NOW\(\) WHERE id = \$3 AND deleted_at IS NULL AND \(\$4::int\[\] IS NULL OR version = ANY\(\$4\)\) RETURNING`).
+		WithArgs(nil, "Jane", userID, []int(nil)).
+		WillReturnRows(userRows().AddRow(userID, "Jane", "Doe", "jane@example.com", "user", nil, nil, nil, nil, now, now, nil, 3))
+
+	user, err := NewUserServiceInterface(mock, nil).PatchUser(userID, map[string]any{
+		"first_name": "Jane",
+		"city_id":    nil,
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane", user.FirstName)
+	assert.Nil(t, user.CityID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUser_NotPatchableColumn(t *testing.T) {
+	_, err := NewUserServiceInterface(nil, nil).PatchUser(uuid.New(), map[string]any{"password_hash": "x"}, nil)
+
+	assert.ErrorIs(t, err, ErrFieldNotPatchable)
+}
+
+func TestUpdateUser_PreconditionFailed(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	updatedUser := models.User{FirstName: "John", LastName: "Doe", Email: "johndoe@example.com"}
+
+	mock.ExpectQuery(`UPDATE users SET`).
+		WithArgs(updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, userID, []int{1}).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM users WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err = NewUserServiceInterface(mock, nil).UpdateUser(userID, updatedUser, []int{1})
+
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers_OnlyDeleted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM users\s+WHERE deleted_at IS NOT NULL\s+ORDER BY`).
+		WithArgs(DefaultUsersPageLimit + 1).
+		WillReturnRows(userRows())
+
+	_, err = NewUserServiceInterface(mock, nil).ListUsers(models.UserListParams{
+		Filter: models.UserFilter{OnlyDeleted: true},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreUser_EmailTaken(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery(`UPDATE users\s+SET deleted_at = NULL, updated_at = NOW\(\)\s+WHERE id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(userID).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	_, err = NewUserServiceInterface(mock, nil).RestoreUser(userID)
+
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreUser_NotDeleted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery(`UPDATE users`).WithArgs(userID).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM users WHERE id = \$1 AND purged_at IS NULL\)`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err = NewUserServiceInterface(mock, nil).RestoreUser(userID)
+
+	assert.ErrorIs(t, err, ErrUserNotDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEraseUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, adminID := uuid.New(), uuid.New()
+
+	// оригинал и миниатюры аватара удаляются из хранилища после коммита
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/media")
+	avatarDir := filepath.Join(dir, "avatars", userID.String(), "a1")
+	assert.NoError(t, os.MkdirAll(avatarDir, 0o755))
+	for _, name := range []string{"original.jpg", "256.jpg", "64.jpg"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(avatarDir, name), []byte("avatar"), 0o644))
+	}
+	avatarURL := store.URL("avatars/" + userID.String() + "/a1/original.jpg")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT purged_at IS NOT NULL FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"purged"}).AddRow(false))
+	mock.ExpectQuery(`SELECT avatar_url FROM user_profiles WHERE user_id = \$1 AND avatar_url IS NOT NULL`).
+		WithArgs(userID).WillReturnRows(pgxmock.NewRows([]string{"avatar_url"}).AddRow(avatarURL))
+	mock.ExpectExec(`UPDATE user_profiles\s+SET phone = NULL, phone_verified_at = NULL, address = NULL, bio = NULL, avatar_url = NULL`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`DELETE FROM user_preferences WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`DELETE FROM user_travel_preferences WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec(`DELETE FROM user_favorite_hotels WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec(`DELETE FROM travel_documents WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`DELETE FROM user_companions WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`DELETE FROM phone_verifications WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec(`DELETE FROM oauth_sessions WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec(`DELETE FROM auth_codes WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectQuery(`DELETE FROM data_exports WHERE user_id = \$1 RETURNING`).
+		WithArgs(userID).WillReturnRows(pgxmock.NewRows([]string{"file_path"}))
+	mock.ExpectExec(`UPDATE security_events SET ip_address = NULL, user_agent = NULL WHERE user_id = \$1`).
+		WithArgs(userID).WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+	mock.ExpectExec(`UPDATE users\s+SET email = 'deleted-' \|\| id \|\| '@invalid'`).
+		WithArgs([]uuid.UUID{userID}).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "user.erased", "user", &userID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	err = NewUserService(mock, nil, nil, store).EraseUser(userID, models.AuditLog{ActorID: &adminID})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	entries, err := os.ReadDir(avatarDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestEraseUser_AlreadyErased(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT purged_at IS NOT NULL FROM users`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"purged"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = NewUserServiceInterface(mock, nil).EraseUser(userID, models.AuditLog{})
+
+	assert.ErrorIs(t, err, ErrUserAlreadyErased)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchProfile_Upsert(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	phone := "+4915112345678"
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT country_id FROM users WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"country_id"}).AddRow(nil))
+	// смена телефона сбрасывает подтверждение
+	mock.ExpectQuery(`INSERT INTO user_profiles \(user_id, address, phone\)\s+SELECT id, \$2, \$3 FROM users WHERE id = \$1 AND deleted_at IS NULL\s+ON CONFLICT \(user_id\) DO UPDATE SET updated_at = NOW\(\), address = EXCLUDED.address, phone = EXCLUDED.phone, phone_verified_at = CASE\s+WHEN user_profiles.phone IS DISTINCT FROM EXCLUDED.phone THEN NULL`).
+		WithArgs(userID, nil, phone).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "phone", "phone_verified_at", "address", "bio", "avatar_url", "created_at", "updated_at"}).
+			AddRow(uuid.New(), userID, &phone, nil, nil, nil, nil, now, now))
+
+	profile, err := NewUserServiceInterface(mock, nil).PatchProfile(userID, map[string]any{"phone": "+49 151 1234 5678", "address": nil})
+
+	assert.NoError(t, err)
+	assert.Equal(t, phone, *profile.Phone)
+	assert.Nil(t, profile.Address)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchProfile_NationalPhoneWithoutCountry(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery(`SELECT country_id FROM users`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"country_id"}).AddRow(nil))
+
+	_, err = NewUserServiceInterface(mock, nil).PatchProfile(userID, map[string]any{"phone": "0151 12345678"})
+
+	assert.ErrorIs(t, err, ErrInvalidPhone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchProfile_DeletedUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery(`INSERT INTO user_profiles`).WithArgs(userID, "bio").WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewUserServiceInterface(mock, nil).PatchProfile(userID, map[string]any{"bio": "bio"})
+
+	assert.EqualError(t, err, "user not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentities_GroupsByUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	first, second := uuid.New(), uuid.New()
+	mock.ExpectQuery(`FROM oauth_sessions\s+WHERE user_id = ANY\(\$1\)`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "provider", "provider_id"}).
+			AddRow(first, "github", "gh-1").
+			AddRow(first, "google", "g-1"))
+
+	identities, err := NewUserServiceInterface(mock, nil).GetIdentities([]uuid.UUID{first, second})
+
+	assert.NoError(t, err)
+	assert.Len(t, identities[first], 2)
+	assert.NotContains(t, identities, second)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	lastLogin := time.Now()
+	mock.ExpectQuery(`FROM users u\s+WHERE u.id = ANY\(\$1\)`).
+		WithArgs([]uuid.UUID{userID}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "login_count", "last_login_at", "security_event_count"}).
+			AddRow(userID, int64(4), &lastLogin, int64(1)))
+
+	stats, err := NewUserServiceInterface(mock, nil).GetStats([]uuid.UUID{userID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStats{LoginCount: 4, LastLoginAt: &lastLogin, SecurityEventCount: 1}, stats[userID])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers_SparseFieldsetSelectsOnlyNeededColumns(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, cityID := uuid.New(), uuid.New()
+	createdAt := time.Now()
+
+	// city -> city_id, плюс id и колонка сортировки для курсора
+	mock.ExpectQuery(`SELECT id, first_name, city_id, created_at\s+FROM users`).
+		WithArgs(21).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "first_name", "city_id", "created_at"}).
+			AddRow(userID, "John", cityID.String(), createdAt))
+
+	page, err := NewUserServiceInterface(mock, nil).ListUsers(models.UserListParams{
+		Fields: []string{"first_name", "city"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "John", page.Users[0].FirstName)
+	assert.Equal(t, &cityID, page.Users[0].CityID)
+	assert.Empty(t, page.Users[0].Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserFields_InvalidField(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	_, err = NewUserServiceInterface(mock, nil).GetUserFields(uuid.New(), []string{"id", "password_hash"})
+
+	assert.ErrorIs(t, err, ErrInvalidField)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamUsers_AppliesFilterAndFields(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	role := "admin"
+	first, second := uuid.New(), uuid.New()
+	mock.ExpectQuery(`SELECT id, email\s+FROM users\s+WHERE deleted_at IS NULL AND role = \$1\s+ORDER BY id`).
+		WithArgs(role).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email"}).
+			AddRow(first, "a@example.com").
+			AddRow(second, "b@example.com"))
+
+	var emails []string
+	err = NewUserServiceInterface(mock, nil).StreamUsers(context.Background(), models.UserFilter{Role: &role}, []string{"email"},
+		func(user models.User) error {
+			emails = append(emails, user.Email)
+			return nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, emails)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersByIDs_SingleAnyQuery(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	first, second := uuid.New(), uuid.New()
+	mock.ExpectQuery(`SELECT id, email, version\s+FROM users\s+WHERE id = ANY\(\$1\) AND deleted_at IS NULL`).
+		WithArgs([]uuid.UUID{first, second}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email", "version"}).
+			AddRow(second, "jane@example.com", 3))
+
+	users, err := NewUserServiceInterface(mock, nil).GetUsersByIDs([]uuid.UUID{first, second}, []string{"email"})
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, second, users[0].ID)
+	assert.Equal(t, "jane@example.com", users[0].Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+var (
+	ErrInvalidStatus        = errors.New("invalid status")
+	ErrStatusTransition     = errors.New("status transition is not allowed")
+	ErrStatusReasonRequired = errors.New("reason is required to suspend or ban an account")
+	ErrInvalidSuspensionEnd = errors.New("until is only allowed for suspensions and must be in the future")
+	ErrOwnStatusChange      = errors.New("admins cannot change the status of their own account")
+)
+
+// userBlockedCondition — true for users whose status does not allow signing in, kept in sync
+// with models.UserStatus.Effective
+const userBlockedCondition = `(status IN ('pending', 'banned') OR (status = 'suspended' AND (status_until IS NULL OR status_until > NOW())))`
+
+// userActiveCondition — true for users that exist, are not deleted and may sign in
+const userActiveCondition = `deleted_at IS NULL AND NOT ` + userBlockedCondition
+
+// UserStatusService — account status lifecycle, changed by admins only
+type UserStatusService struct {
+	db  db_interface
+	now func() time.Time
+}
+
+// NewUserStatusService creates new service
+func NewUserStatusService(db db_interface) *UserStatusService {
+	return &UserStatusService{db: db, now: time.Now}
+}
+
+// GetStatus - effective status of an active (not deleted) user
+func (s *UserStatusService) GetStatus(ctx context.Context, userID uuid.UUID) (models.UserStatus, error) {
+	status, err := loadUserStatus(ctx, s.db, userID, false)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	return status.Effective(s.now()), nil
+}
+
+// ChangeStatus - moves the account to a new status if the transition is allowed; until only bounds
+// suspensions, reason is required for suspensions and bans. The change is written to the audit log
+func (s *UserStatusService) ChangeStatus(ctx context.Context, userID uuid.UUID, to, reason string, until *time.Time, audit models.AuditLog) (models.UserStatus, error) {
+	switch to {
+	case models.UserStatusPending, models.UserStatusActive, models.UserStatusSuspended, models.UserStatusBanned:
+	default:
+		return models.UserStatus{}, ErrInvalidStatus
+	}
+	if (to == models.UserStatusSuspended || to == models.UserStatusBanned) && reason == "" {
+		return models.UserStatus{}, ErrStatusReasonRequired
+	}
+	now := s.now()
+	if until != nil && (to != models.UserStatusSuspended || !until.After(now)) {
+		return models.UserStatus{}, ErrInvalidSuspensionEnd
+	}
+	if audit.ActorID != nil && *audit.ActorID == userID {
+		return models.UserStatus{}, ErrOwnStatusChange
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := loadUserStatus(ctx, tx, userID, true)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	from := current.Effective(now).Status
+	if !models.CanTransitionUserStatus(from, to) {
+		return models.UserStatus{}, ErrStatusTransition
+	}
+
+	updated := models.UserStatus{Status: to, Reason: reason, Until: until}
+	err = tx.QueryRow(ctx, `
+		UPDATE users
+		SET status = $1, status_reason = NULLIF($2, ''), status_until = $3, status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $4
+		RETURNING status_changed_at`, to, reason, until, userID).Scan(&updated.ChangedAt)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+
+	if audit.Metadata == nil {
+		audit.Metadata = map[string]any{}
+	}
+	audit.Metadata["from"] = from
+	audit.Metadata["to"] = to
+	if until != nil {
+		audit.Metadata["until"] = until.UTC().Format(time.RFC3339)
+	}
+	audit.Action = models.AuditActionUserStatusChanged
+	audit.TargetType = models.AuditTargetUser
+	audit.TargetID = &userID
+
+	if err := insertAuditLog(ctx, tx, audit); err != nil {
+		return models.UserStatus{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.UserStatus{}, err
+	}
+	return updated, nil
+}
+
+// loadUserStatus — stored (not effective) status of an active user, optionally locked for update
+func loadUserStatus(ctx context.Context, db db_interface, userID uuid.UUID, forUpdate bool) (models.UserStatus, error) {
+	query := `SELECT status, COALESCE(status_reason, ''), status_until, status_changed_at
+			  FROM users WHERE id = $1 AND deleted_at IS NULL`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var status models.UserStatus
+	err := db.QueryRow(ctx, query, userID).Scan(&status.Status, &status.Reason, &status.Until, &status.ChangedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserStatus{}, errors.New("user not found")
+		}
+		return models.UserStatus{}, err
+	}
+	return status, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitalii-q/selena-users-service/internal/models"
+)
+
+var statusColumns = []string{"status", "status_reason", "status_until", "status_changed_at"}
+
+func newTestUserStatusService(mock pgxmock.PgxPoolIface, now time.Time) *UserStatusService {
+	service := NewUserStatusService(mock)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestChangeStatus_Suspend(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, adminID := uuid.New(), uuid.New()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(7 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(statusColumns).AddRow("active", "", (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectQuery(`UPDATE users\s+SET status = \$1, status_reason = NULLIF\(\$2, ''\), status_until = \$3`).
+		WithArgs("suspended", "chargeback fraud", &until, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"status_changed_at"}).AddRow(&now))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "user.status_changed", "user", &userID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	status, err := newTestUserStatusService(mock, now).ChangeStatus(context.Background(), userID,
+		models.UserStatusSuspended, "chargeback fraud", &until, models.AuditLog{ActorID: &adminID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatus{Status: "suspended", Reason: "chargeback fraud", Until: &until, ChangedAt: &now}, status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// импортированный pending-аккаунт активирует админ
+func TestChangeStatus_ActivatePending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID, adminID := uuid.New(), uuid.New()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(statusColumns).AddRow("pending", "", (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectQuery(`UPDATE users\s+SET status = \$1`).
+		WithArgs("active", "", (*time.Time)(nil), userID).
+		WillReturnRows(pgxmock.NewRows([]string{"status_changed_at"}).AddRow(&now))
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(&adminID, "user.status_changed", "user", &userID, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	status, err := newTestUserStatusService(mock, now).ChangeStatus(context.Background(), userID,
+		models.UserStatusActive, "", nil, models.AuditLog{ActorID: &adminID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatus{Status: "active", ChangedAt: &now}, status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// обратно в pending перевести нельзя
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(statusColumns).AddRow("active", "", (*time.Time)(nil), &now))
+	mock.ExpectRollback()
+
+	_, err = newTestUserStatusService(mock, now).ChangeStatus(context.Background(), userID,
+		models.UserStatusPending, "", nil, models.AuditLog{ActorID: &adminID})
+	assert.ErrorIs(t, err, ErrStatusTransition)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangeStatus_ExpiredSuspensionCountsAsActive(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(statusColumns).AddRow("suspended", "spam", &expired, &expired))
+	// suspended -> active уже произошло по времени, значит active -> active недопустим
+	mock.ExpectRollback()
+
+	_, err = newTestUserStatusService(mock, now).ChangeStatus(context.Background(), userID,
+		models.UserStatusActive, "", nil, models.AuditLog{})
+
+	assert.ErrorIs(t, err, ErrStatusTransition)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangeStatus_BannedIsTerminal(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(statusColumns).AddRow("banned", "abuse", (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectRollback()
+
+	_, err = newTestUserStatusService(mock, time.Now()).ChangeStatus(context.Background(), userID,
+		models.UserStatusActive, "appeal", nil, models.AuditLog{})
+
+	assert.ErrorIs(t, err, ErrStatusTransition)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangeStatus_InvalidInput(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	tests := map[string]struct {
+		status string
+		reason string
+		until  *time.Time
+		actor  *uuid.UUID
+		want   error
+	}{
+		"unknown status":     {status: "deleted", want: ErrInvalidStatus},
+		"ban without reason": {status: "banned", want: ErrStatusReasonRequired},
+		"until in the past":  {status: "suspended", reason: "spam", until: &past, want: ErrInvalidSuspensionEnd},
+		"until for a ban":    {status: "banned", reason: "spam", until: &future, want: ErrInvalidSuspensionEnd},
+		"own account":        {status: "banned", reason: "oops", actor: &userID, want: ErrOwnStatusChange},
+	}
+
+	service := newTestUserStatusService(mock, now)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.ChangeStatus(context.Background(), userID, tt.status, tt.reason, tt.until, models.AuditLog{ActorID: tt.actor})
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+	// до БД дело не доходит
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectImageType(t *testing.T) {
+	assert.Equal(t, "image/jpeg", DetectImageType([]byte{0xFF, 0xD8, 0xFF, 0xE0}))
+	assert.Equal(t, "image/png", DetectImageType([]byte("\x89PNG\r\n\x1a\n....")))
+	assert.Equal(t, "image/webp", DetectImageType([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	assert.Equal(t, "", DetectImageType([]byte("GIF89a")))
+	assert.Equal(t, "", DetectImageType([]byte("<svg></svg>")))
+}
+
+func TestDecodeImage_RejectsMismatchedContent(t *testing.T) {
+	// PNG магия, но внутри мусор
+	_, err := DecodeImage([]byte("\x89PNG\r\n\x1a\nnot really a png"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+	_, err = DecodeImage([]byte("<?php echo 1; ?>"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+// jpegWithOrientation — 2x1 JPEG с APP1 EXIF сегментом (orientation), вставленным после SOI
+func jpegWithOrientation(t *testing.T, orientation uint16) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 1)), nil))
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3) // SHORT
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	payload := append(append([]byte("Exif\x00\x00"), tiff...), entry...)
+	payload = append(payload, 0, 0, 0, 0) // next IFD
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	assert.Equal(t, 6, JPEGOrientation(jpegWithOrientation(t, 6)))
+	assert.Equal(t, 1, JPEGOrientation(jpegWithOrientation(t, 42)))
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))))
+	assert.Equal(t, 1, JPEGOrientation(buf.Bytes()))
+}
+
+func TestOrient_Rotate90(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red := color.NRGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	rotated := Orient(img, 6)
+	assert.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	// левый пиксель после поворота на 90° по часовой оказывается сверху
+	assert.Equal(t, red, rotated.NRGBAAt(0, 0))
+}
+
+func TestFitAndThumbnail(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	assert.Equal(t, image.Rect(0, 0, 100, 50), FitImage(img, 100).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 400, 200), FitImage(img, 1000).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 64, 64), SquareThumbnail(img, 64).Bounds())
+}
+
+func TestEncodeImage_PicksFormatByTransparency(t *testing.T) {
+	opaque := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := 3; i < len(opaque.Pix); i += 4 {
+		opaque.Pix[i] = 255
+	}
+	_, contentType, ext, err := EncodeImage(opaque)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Equal(t, ".jpg", ext)
+
+	_, contentType, _, err = EncodeImage(image.NewNRGBA(image.Rect(0, 0, 2, 2)))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+}
@@ -1,12 +1,53 @@
 package utils
 
 import (
+	"errors"
+	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("your-secret") // вынеси в env
+var jwtSecret = loadJWTSecret()
+
+// ErrJWTSecretMissing — JWT_SECRET is empty and the dev fallback was not allowed explicitly
+var ErrJWTSecretMissing = errors.New("JWT_SECRET is not set (JWT_ALLOW_DEV_SECRET=true allows the insecure dev secret)")
+
+// loadJWTSecret — reads the signing secret from JWT_SECRET (dev fallback otherwise, see CheckJWTSecret)
+func loadJWTSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte("your-secret")
+}
+
+// CheckJWTSecret — called at startup: tokens carry roles and impersonation claims, so the publicly
+// known dev secret is only used when JWT_ALLOW_DEV_SECRET=true
+func CheckJWTSecret() error {
+	if os.Getenv("JWT_SECRET") == "" && os.Getenv("JWT_ALLOW_DEV_SECRET") != "true" {
+		return ErrJWTSecretMissing
+	}
+	return nil
+}
+
+// ActorClaim — RFC 8693 "act" claim, identifies who really acts behind the token
+type ActorClaim struct {
+	Sub string `json:"sub"`
+}
+
+// TokenClaims — claims of access tokens issued by users-service
+type TokenClaims struct {
+	UserID string      `json:"user_id"`
+	Role   string      `json:"role,omitempty"`
+	Act    *ActorClaim `json:"act,omitempty"` // set only for impersonation tokens
+	jwt.RegisteredClaims
+}
+
+// IsImpersonation — true if the token was issued to an admin acting as another user
+func (c *TokenClaims) IsImpersonation() bool {
+	return c.Act != nil && c.Act.Sub != ""
+}
 
 func GenerateJWT(userID string) (string, error) {
 	claims := jwt.MapClaims{
@@ -17,3 +58,41 @@ func GenerateJWT(userID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// GenerateAccessToken — signs claims with a fresh jti and the given lifetime
+func GenerateAccessToken(claims TokenClaims, ttl time.Duration) (string, *TokenClaims, error) {
+	now := time.Now()
+
+	claims.ID = uuid.NewString()
+	claims.Subject = claims.UserID
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, &claims, nil
+}
+
+// ParseAccessToken — verifies signature and expiry and returns the token claims
+func ParseAccessToken(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.UserID == "" {
+		claims.UserID = claims.Subject
+	}
+
+	return claims, nil
+}
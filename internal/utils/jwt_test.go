@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_ALLOW_DEV_SECRET", "")
+	assert.ErrorIs(t, CheckJWTSecret(), ErrJWTSecretMissing)
+
+	// dev-секрет только по явному флагу
+	t.Setenv("JWT_ALLOW_DEV_SECRET", "true")
+	assert.NoError(t, CheckJWTSecret())
+
+	t.Setenv("JWT_ALLOW_DEV_SECRET", "")
+	t.Setenv("JWT_SECRET", "s3cr3t")
+	assert.NoError(t, CheckJWTSecret())
+}
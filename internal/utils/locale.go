@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+	_ "time/tzdata" // IANA database embedded, container images may ship without it
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidLanguage = errors.New("invalid language, expected a BCP 47 tag such as en or de-AT")
+	ErrInvalidCurrency = errors.New("invalid currency, expected an ISO 4217 code such as EUR")
+	ErrInvalidTimezone = errors.New("invalid timezone, expected an IANA name such as Europe/Berlin")
+	ErrInvalidCountry  = errors.New("invalid country, expected an ISO 3166-1 alpha-2 code such as DE")
+)
+
+// NormalizeLanguageTag — well-formed BCP 47 tag in canonical form: "de_at" -> "de-AT"
+func NormalizeLanguageTag(value string) (string, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "_", "-")
+	if value == "" {
+		return "", ErrInvalidLanguage
+	}
+
+	tag, err := language.Parse(value)
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLanguage
+	}
+	return tag.String(), nil
+}
+
+// NormalizeCurrency — upper-case ISO 4217 code of a currency in use: "eur" -> "EUR"
+func NormalizeCurrency(value string) (string, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) != 3 {
+		return "", ErrInvalidCurrency
+	}
+
+	unit, err := currency.ParseISO(value)
+	if err != nil {
+		return "", ErrInvalidCurrency
+	}
+	return unit.String(), nil
+}
+
+// NormalizeTimezone — IANA zone name as given ("Europe/Berlin"); "Local" and abbreviations
+// such as "CET" are rejected, only Region/City names and UTC are accepted
+func NormalizeTimezone(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "UTC" {
+		return value, nil
+	}
+	if !strings.Contains(value, "/") {
+		return "", ErrInvalidTimezone
+	}
+
+	if _, err := time.LoadLocation(value); err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return value, nil
+}
+
+// NormalizeCountryCode — upper-case ISO 3166-1 alpha-2 code of a country: "at" -> "AT"
+func NormalizeCountryCode(value string) (string, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) != 2 {
+		return "", ErrInvalidCountry
+	}
+
+	region, err := language.ParseRegion(value)
+	if err != nil || !region.IsCountry() {
+		return "", ErrInvalidCountry
+	}
+	return region.String(), nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLanguageTag(t *testing.T) {
+	for raw, expected := range map[string]string{
+		"en":         "en",
+		"de_at":      "de-AT",
+		"PT-br":      "pt-BR",
+		"zh-Hant-TW": "zh-Hant-TW",
+	} {
+		tag, err := NormalizeLanguageTag(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, expected, tag, raw)
+	}
+
+	for _, raw := range []string{"", "und", "english", "en-", "12"} {
+		_, err := NormalizeLanguageTag(raw)
+		assert.ErrorIs(t, err, ErrInvalidLanguage, raw)
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	code, err := NormalizeCurrency(" eur ")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", code)
+
+	for _, raw := range []string{"", "EURO", "XYZ", "€"} {
+		_, err := NormalizeCurrency(raw)
+		assert.ErrorIs(t, err, ErrInvalidCurrency, raw)
+	}
+}
+
+func TestNormalizeTimezone(t *testing.T) {
+	for _, raw := range []string{"UTC", "Europe/Berlin", "America/Argentina/Buenos_Aires"} {
+		zone, err := NormalizeTimezone(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, raw, zone)
+	}
+
+	// аббревиатуры и Local зависят от окружения, принимаются только имена IANA
+	for _, raw := range []string{"", "Local", "CET", "Europe/Atlantis", "../etc/passwd"} {
+		_, err := NormalizeTimezone(raw)
+		assert.ErrorIs(t, err, ErrInvalidTimezone, raw)
+	}
+}
+
+func TestNormalizeCountryCode(t *testing.T) {
+	code, err := NormalizeCountryCode(" at ")
+	assert.NoError(t, err)
+	assert.Equal(t, "AT", code)
+
+	for _, raw := range []string{"", "AUT", "ZZ", "1A", "EU"} {
+		_, err := NormalizeCountryCode(raw)
+		assert.ErrorIs(t, err, ErrInvalidCountry, raw)
+	}
+}
+
+func TestMaskTail(t *testing.T) {
+	assert.Equal(t, "****4567", MaskTail("X1234567", 4))
+	assert.Equal(t, "***", MaskTail("123", 4))
+	assert.Equal(t, "", MaskTail("", 4))
+}
@@ -0,0 +1,13 @@
+package utils
+
+import "strings"
+
+// MaskTail — value with all but the last visible characters replaced by '*': "X1234567" -> "****4567";
+// values not longer than visible are masked entirely, so short ones do not leak
+func MaskTail(value string, visible int) string {
+	runes := []rune(value)
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidPhone        = errors.New("invalid phone number")
+	ErrPhoneRegionRequired = errors.New("phone number without country code, set country_id or use +<country code>")
+)
+
+// phoneRegion — calling code and national trunk prefix of a country
+type phoneRegion struct {
+	callingCode string
+	trunk       string // dropped from national numbers, "" = numbers are dialled as is
+}
+
+// phoneRegions — ISO 3166-1 alpha-2 -> dialling rules, countries missing here need the +<code> form
+var phoneRegions = map[string]phoneRegion{
+	"AT": {"43", "0"}, "BE": {"32", "0"}, "BG": {"359", "0"}, "CH": {"41", "0"},
+	"CY": {"357", ""}, "CZ": {"420", ""}, "DE": {"49", "0"}, "DK": {"45", ""},
+	"EE": {"372", ""}, "ES": {"34", ""}, "FI": {"358", "0"}, "FR": {"33", "0"},
+	"GB": {"44", "0"}, "GR": {"30", ""}, "HR": {"385", "0"}, "HU": {"36", "06"},
+	"IE": {"353", "0"}, "IS": {"354", ""}, "IT": {"39", ""}, "LI": {"423", ""},
+	"LT": {"370", "8"}, "LU": {"352", ""}, "LV": {"371", ""}, "MT": {"356", ""},
+	"NL": {"31", "0"}, "NO": {"47", ""}, "PL": {"48", ""}, "PT": {"351", ""},
+	"RO": {"40", "0"}, "RS": {"381", "0"}, "SE": {"46", "0"}, "SI": {"386", "0"},
+	"SK": {"421", "0"}, "TR": {"90", "0"}, "UA": {"380", "0"}, "RU": {"7", "8"},
+	"KZ": {"7", "8"}, "BY": {"375", "8"}, "GE": {"995", "0"}, "AM": {"374", "0"},
+	"US": {"1", "1"}, "CA": {"1", "1"}, "MX": {"52", ""}, "BR": {"55", "0"},
+	"AR": {"54", "0"}, "CL": {"56", ""}, "CO": {"57", ""}, "AU": {"61", "0"},
+	"NZ": {"64", "0"}, "JP": {"81", "0"}, "KR": {"82", "0"}, "CN": {"86", "0"},
+	"IN": {"91", "0"}, "TH": {"66", "0"}, "VN": {"84", "0"}, "ID": {"62", "0"},
+	"SG": {"65", ""}, "AE": {"971", "0"}, "IL": {"972", "0"}, "EG": {"20", "0"},
+	"ZA": {"27", "0"}, "MA": {"212", "0"},
+}
+
+// NormalizePhone — converts user input to E.164 (+<country code><number>).
+// International input (+49..., 0049...) is accepted as is, national input
+// is resolved with region (ISO alpha-2 code of the user's country).
+func NormalizePhone(raw, region string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+			// visual separators
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	number := b.String()
+
+	var digits string
+	switch {
+	case strings.HasPrefix(number, "+"):
+		digits = number[1:]
+	case strings.HasPrefix(number, "00"):
+		digits = number[2:]
+	default:
+		rules, ok := phoneRegions[strings.ToUpper(region)]
+		if !ok {
+			return "", ErrPhoneRegionRequired
+		}
+		if rules.trunk != "" {
+			number = strings.TrimPrefix(number, rules.trunk)
+		}
+		digits = rules.callingCode + number
+	}
+
+	// E.164: up to 15 digits, country codes never start with 0
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + digits, nil
+}
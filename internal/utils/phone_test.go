@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		raw, region, expected string
+	}{
+		{"+49 151 1234-5678", "", "+4915112345678"},
+		{"0049 151 12345678", "FR", "+4915112345678"},
+		{"0151 12345678", "DE", "+4915112345678"},
+		{"(030) 123 456 78", "de", "+493012345678"},
+		{"06 12 34 56 78", "FR", "+33612345678"},
+		{"06 1234 5678", "IT", "+390612345678"}, // в Италии 0 — часть номера
+		{"8 (912) 345-67-89", "RU", "+79123456789"},
+		{"1 (415) 555-2671", "US", "+14155552671"},
+		{"415.555.2671", "US", "+14155552671"},
+	}
+
+	for _, tc := range cases {
+		phone, err := NormalizePhone(tc.raw, tc.region)
+		assert.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.expected, phone, tc.raw)
+	}
+}
+
+func TestNormalizePhone_Invalid(t *testing.T) {
+	_, err := NormalizePhone("0151 12345678", "")
+	assert.ErrorIs(t, err, ErrPhoneRegionRequired)
+
+	for _, raw := range []string{"+49 abc", "12+34", "+0123456789", "+12345", "+1234567890123456"} {
+		_, err := NormalizePhone(raw, "DE")
+		assert.ErrorIs(t, err, ErrInvalidPhone, raw)
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+)
+
+var urlSigningKey = loadURLSigningKey()
+
+// loadURLSigningKey — URL_SIGNING_KEY, falls back to the JWT secret
+func loadURLSigningKey() []byte {
+	if key := os.Getenv("URL_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	return jwtSecret
+}
+
+// Sign — HMAC-SHA256 of value, hex encoded (used for signed download links)
+func Sign(value string) string {
+	mac := hmac.New(sha256.New, urlSigningKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature — constant-time check of a Sign result
+func VerifySignature(value, signature string) bool {
+	return hmac.Equal([]byte(Sign(value)), []byte(signature))
+}
@@ -27,7 +27,24 @@ func main() {
 	metrics.Register()
 
 	// --- Router setup ---
-	r := router.SetupRouter(deps.DB, deps.UserHandler, deps.AuthHandler, deps.UserHotelsHandler, deps.LocationsHandler)
+	r := router.SetupRouter(
+		deps.DB,
+		deps.AuthService,
+		deps.UserHandler,
+		deps.AuthHandler,
+		deps.UserHotelsHandler,
+		deps.LocationsHandler,
+		deps.ImpersonationHandler,
+		deps.DataExportHandler,
+		deps.PhoneVerificationHandler,
+		deps.AvatarHandler,
+		deps.UserImportHandler,
+		deps.UserStatusHandler,
+		deps.PreferencesHandler,
+		deps.TravelHandler,
+		deps.CompanionHandler,
+		deps.TravelDocumentHandler,
+	)
 
 	// --- HTTP server ---
 	srv := server.StartServer(r)